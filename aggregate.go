@@ -0,0 +1,129 @@
+// aggregate.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Count returns the number of records in the table matching filter (all records if filter is empty).
+// The filter uses the same equality model as List. Servers without the count capability
+// fail with ErrUnsupportedByServer.
+func (s *RecordService) Count(ctx context.Context, dbName, tableName string, filter Filter) (int64, error) {
+	apiPath, err := s.buildRecordPath("Records.Count", dbName, tableName)
+	if err != nil {
+		return 0, err
+	}
+	apiPath += "/count"
+	if err := s.client.requireCapability("Records.Count", CapabilityCount); err != nil {
+		return 0, err
+	}
+
+	queryValues := url.Values{}
+	if err := addFilterQuery("Records.Count", queryValues, filter); err != nil {
+		return 0, err
+	}
+	if len(queryValues) > 0 {
+		apiPath = fmt.Sprintf("%s?%s", apiPath, queryValues.Encode())
+	}
+
+	var result CountRecordsResponse // Expecting {"count": N}
+	err = s.client.doRequest(ctx, "Records.Count", http.MethodGet, apiPath, nil, &result)
+	if err != nil {
+		// Handles 400 (invalid filter), 401, 404 (db/table not found, or no count endpoint), 500
+		return 0, unsupportedByServer("Records.Count", CapabilityCount, err)
+	}
+	return result.Count, nil
+}
+
+// Aggregate computes COUNT, SUM, AVG, MIN and MAX aggregations over the records
+// matching query.Filter, optionally grouped by one or more columns.
+// Aggregations without an Alias get a default one ("count", "sum_quantity", ...).
+// Servers without the aggregate capability fail with ErrUnsupportedByServer.
+//
+//	rows, err := client.Records.Aggregate(ctx, "shop", "orders", nebula.AggregateQuery{
+//		Aggregations: []nebula.Aggregation{{Func: nebula.AggregateSum, Column: "total"}},
+//		GroupBy:      []string{"status"},
+//	})
+func (s *RecordService) Aggregate(ctx context.Context, dbName, tableName string, query AggregateQuery) ([]AggregateRow, error) {
+	apiPath, err := s.buildRecordPath("Records.Aggregate", dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	apiPath += "/aggregate"
+	if err := s.client.requireCapability("Records.Aggregate", CapabilityAggregate); err != nil {
+		return nil, err
+	}
+
+	query.Aggregations = withDefaultAliases(query.Aggregations)
+	if err := validateAggregateQuery(query).err("Records.Aggregate"); err != nil {
+		return nil, err
+	}
+
+	var result AggregateResponse // Expecting {"rows": [{"group": {...}, "values": {...}}, ...]}
+	err = s.client.doRequest(ctx, "Records.Aggregate", http.MethodPost, apiPath, query, &result, readOnlyRequest())
+	if err != nil {
+		// Handles 400 (unknown column, non-numeric SUM/AVG), 401, 404 (db/table not found, or no aggregate endpoint), 500
+		return nil, unsupportedByServer("Records.Aggregate", CapabilityAggregate, err)
+	}
+
+	if result.Rows == nil {
+		return make([]AggregateRow, 0), nil
+	}
+	return result.Rows, nil
+}
+
+// withDefaultAliases returns a copy of aggs with empty aliases filled in.
+func withDefaultAliases(aggs []Aggregation) []Aggregation {
+	out := make([]Aggregation, len(aggs))
+	for i, agg := range aggs {
+		agg.Func = AggregateFunc(strings.ToUpper(string(agg.Func)))
+		if agg.Alias == "" {
+			agg.Alias = strings.ToLower(string(agg.Func))
+			if agg.Column != "" {
+				agg.Alias += "_" + agg.Column
+			}
+		}
+		out[i] = agg
+	}
+	return out
+}
+
+// validateAggregateQuery checks aggregate functions, columns, aliases, group-by columns and filter keys.
+func validateAggregateQuery(query AggregateQuery) fieldErrors {
+	var errs fieldErrors
+	if len(query.Aggregations) == 0 {
+		errs.add("aggregations", FieldCodeRequired, "at least one aggregation is required")
+	}
+
+	aliases := make(map[string]bool, len(query.Aggregations))
+	for i, agg := range query.Aggregations {
+		field := fmt.Sprintf("aggregations[%d]", i)
+		switch agg.Func {
+		case AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+		default:
+			errs.add(field+".func", FieldCodeUnsupported, "unsupported aggregate function %q (expected COUNT, SUM, AVG, MIN or MAX)", agg.Func)
+		}
+		if agg.Column == "" && agg.Func != AggregateCount {
+			errs.add(field+".column", FieldCodeRequired, "%s requires a column", agg.Func)
+		} else if agg.Column != "" && !identifierPattern.MatchString(agg.Column) {
+			errs.add(field+".column", FieldCodeInvalid, "column name %q is not valid", agg.Column)
+		}
+		if !identifierPattern.MatchString(agg.Alias) {
+			errs.add(field+".alias", FieldCodeInvalid, "alias %q is not a valid name", agg.Alias)
+		} else if aliases[agg.Alias] {
+			errs.add(field+".alias", FieldCodeDuplicate, "alias %q is used more than once", agg.Alias)
+		}
+		aliases[agg.Alias] = true
+	}
+
+	for i, col := range query.GroupBy {
+		if !identifierPattern.MatchString(col) {
+			errs.add(fmt.Sprintf("group_by[%d]", i), FieldCodeInvalid, "group-by column %q is not valid", col)
+		}
+	}
+	return append(errs, validateFilters(query.Filter)...)
+}
@@ -26,7 +26,7 @@ func (s *AuthService) Signup(ctx context.Context, email, password string) error
 	apiPath := "auth/signup" // Path relative to base URL
 
 	// Use doRequest helper. No response body expected on success (201).
-	err := s.client.doRequest(ctx, http.MethodPost, apiPath, payload, nil)
+	err := s.client.doRequest(ctx, "Auth.Signup", http.MethodPost, apiPath, payload, nil)
 	if err != nil {
 		// doRequest already maps API errors (e.g., 409 Conflict)
 		return err
@@ -51,7 +51,7 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 	var result LoginResponse // Define where to store the successful response body
 
 	// Use doRequest helper, passing pointer to result struct.
-	err := s.client.doRequest(ctx, http.MethodPost, apiPath, payload, &result)
+	err := s.client.doRequest(ctx, "Auth.Login", http.MethodPost, apiPath, payload, &result)
 	if err != nil {
 		// doRequest maps API errors (e.g., 401, 404)
 		s.client.ClearAuthToken() // Ensure token is cleared on failed login attempt
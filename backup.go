@@ -0,0 +1,449 @@
+// backup.go
+package nebula
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	backupFormatVersion  = 1               // Bumped on incompatible archive layout changes
+	backupManifestName   = "manifest.json" // Always the first archive entry
+	maxBackupSchemaBytes = 1 * 1024 * 1024 // Limit schema entries to 1MB
+	defaultRestoreBatch  = 100
+)
+
+// BackupManifest describes the contents of a backup archive. It is the first entry
+// of the archive, so Restore can validate the archive before changing anything.
+type BackupManifest struct {
+	FormatVersion int           `json:"format_version"`
+	Database      string        `json:"database"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Tables        []BackupTable `json:"tables"`
+}
+
+// BackupTable describes one table in a backup archive.
+type BackupTable struct {
+	Name         string `json:"name"`
+	RowCount     int64  `json:"row_count"`
+	SchemaFile   string `json:"schema_file"`   // Archive entry holding the SchemaPayload as JSON
+	RowsFile     string `json:"rows_file"`     // Archive entry holding the records as NDJSON, in id order
+	SchemaSHA256 string `json:"schema_sha256"` // Hex SHA-256 of the schema entry
+	RowsSHA256   string `json:"rows_sha256"`   // Hex SHA-256 of the rows entry
+}
+
+// RestoreOptions specifies optional parameters for DatabaseService.Restore.
+type RestoreOptions struct {
+	// Overwrite allows restoring into an existing database: tables contained in the backup
+	// are deleted and recreated, other tables are left untouched. Without it, Restore fails
+	// with ErrDatabaseExists if the target database exists.
+	Overwrite bool
+
+	// BatchSize is the number of rows inserted per transaction. Default 100.
+	BatchSize int
+
+	// SkipVerify skips the final pass comparing each table's row count with the backup.
+	SkipVerify bool
+}
+
+// Backup writes a portable archive of dbName to w: a tar stream holding a manifest, then
+// the schema (JSON) and records (NDJSON, including ids) of every table, with SHA-256
+// checksums in the manifest. Records are spooled to temporary files while the archive is
+// assembled, so memory use doesn't depend on table size.
+// Tables are read one after another, so the backup is not a point-in-time snapshot of
+// a database that is being written to.
+func (s *DatabaseService) Backup(ctx context.Context, dbName string, w io.Writer) (*BackupManifest, error) {
+	tableNames, err := s.client.Tables.List(ctx, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("backup: listing tables of %q: %w", dbName, err)
+	}
+	sort.Strings(tableNames)
+
+	manifest := &BackupManifest{
+		FormatVersion: backupFormatVersion,
+		Database:      dbName,
+		CreatedAt:     time.Now().UTC(),
+		Tables:        make([]BackupTable, 0, len(tableNames)),
+	}
+	schemas := make([][]byte, 0, len(tableNames))
+	spools := make([]string, 0, len(tableNames)) // Temporary files holding each table's rows
+	defer func() {
+		for _, path := range spools {
+			os.Remove(path)
+		}
+	}()
+
+	for _, tableName := range tableNames {
+		schema, err := s.client.Tables.GetSchema(ctx, dbName, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("backup: reading schema of table %q: %w", tableName, err)
+		}
+		schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("backup: encoding schema of table %q: %w", tableName, err)
+		}
+
+		spool, err := os.CreateTemp("", "nebula-backup-*.ndjson")
+		if err != nil {
+			return nil, fmt.Errorf("backup: %w", err)
+		}
+		spools = append(spools, spool.Name())
+		rowCount, rowsSum, err := s.spoolRows(ctx, dbName, tableName, spool)
+		if closeErr := spool.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("backup: %w", closeErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		schemaSum := sha256.Sum256(schemaJSON)
+		schemas = append(schemas, schemaJSON)
+		manifest.Tables = append(manifest.Tables, BackupTable{
+			Name:         tableName,
+			RowCount:     rowCount,
+			SchemaFile:   "tables/" + tableName + "/schema.json",
+			RowsFile:     "tables/" + tableName + "/rows.ndjson",
+			SchemaSHA256: hex.EncodeToString(schemaSum[:]),
+			RowsSHA256:   rowsSum,
+		})
+	}
+
+	// Assemble the archive: manifest first, then each table's schema and rows
+	tw := tar.NewWriter(w)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("backup: encoding manifest: %w", err)
+	}
+	if err := writeTarEntry(tw, backupManifestName, manifest.CreatedAt, int64(len(manifestJSON)), bytes.NewReader(manifestJSON)); err != nil {
+		return nil, err
+	}
+	for i, table := range manifest.Tables {
+		if err := writeTarEntry(tw, table.SchemaFile, manifest.CreatedAt, int64(len(schemas[i])), bytes.NewReader(schemas[i])); err != nil {
+			return nil, err
+		}
+		if err := copySpoolToTar(tw, table.RowsFile, manifest.CreatedAt, spools[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("backup: writing archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// spoolRows streams every record of the table, in id order, to f as NDJSON, returning
+// the row count and the hex SHA-256 of what was written.
+func (s *DatabaseService) spoolRows(ctx context.Context, dbName, tableName string, f *os.File) (int64, string, error) {
+	hash := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(f, hash))
+	enc := json.NewEncoder(buf)
+	sortBy, sortDir := "id", "asc"
+
+	var rowCount int64
+	err := s.client.Records.Stream(ctx, dbName, tableName, &ListRecordsOptions{SortBy: &sortBy, SortDirection: &sortDir}, func(record Record) error {
+		rowCount++
+		return enc.Encode(record)
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("backup: reading records of table %q: %w", tableName, err)
+	}
+	if err := buf.Flush(); err != nil {
+		return 0, "", fmt.Errorf("backup: %w", err)
+	}
+	return rowCount, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Restore recreates a database from an archive written by Backup: it creates targetDB,
+// defines every table's schema, reloads the records (keeping their ids) in transactions
+// of opts.BatchSize rows and, unless opts.SkipVerify is set, checks that each table's row
+// count matches the backup (ErrRestoreMismatch otherwise), streaming the rows to count
+// them on servers without Records.Count. Entries are checked against the
+// manifest's checksums before they are applied; a damaged archive yields ErrBackupCorrupt.
+// It returns the archive's manifest.
+func (s *DatabaseService) Restore(ctx context.Context, targetDB string, r io.Reader, opts *RestoreOptions) (*BackupManifest, error) {
+	var o RestoreOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultRestoreBatch
+	}
+
+	tr := tar.NewReader(r)
+	manifest, err := readBackupManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*BackupTable, 2*len(manifest.Tables)) // Keyed by archive entry name
+	for i := range manifest.Tables {
+		table := &manifest.Tables[i]
+		if err := validateTableName(table.Name).err("Databases.Restore"); err != nil {
+			return manifest, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+		}
+		tables[table.SchemaFile] = table
+		tables[table.RowsFile] = table
+	}
+
+	if err := s.prepareRestoreTarget(ctx, targetDB, manifest, o.Overwrite); err != nil {
+		return manifest, err
+	}
+
+	defined := make(map[string]bool, len(manifest.Tables))
+	loaded := make(map[string]bool, len(manifest.Tables))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+		}
+		table, ok := tables[hdr.Name]
+		if !ok {
+			continue // Entries from newer archive layouts are ignored
+		}
+
+		if hdr.Name == table.SchemaFile {
+			if err := s.restoreSchema(ctx, targetDB, table, tr); err != nil {
+				return manifest, err
+			}
+			defined[table.Name] = true
+			continue
+		}
+		if !defined[table.Name] {
+			return manifest, fmt.Errorf("%w: rows of table %q precede its schema", ErrBackupCorrupt, table.Name)
+		}
+		if err := s.restoreRows(ctx, targetDB, table, tr, o.BatchSize); err != nil {
+			return manifest, err
+		}
+		loaded[table.Name] = true
+	}
+
+	for _, table := range manifest.Tables {
+		if !defined[table.Name] || !loaded[table.Name] {
+			return manifest, fmt.Errorf("%w: entries for table %q are missing", ErrBackupCorrupt, table.Name)
+		}
+	}
+
+	if !o.SkipVerify {
+		for _, table := range manifest.Tables {
+			count, err := s.countRows(ctx, targetDB, table.Name)
+			if err != nil {
+				return manifest, fmt.Errorf("restore: verifying table %q: %w", table.Name, err)
+			}
+			if count != table.RowCount {
+				return manifest, fmt.Errorf("%w: table %q has %d rows, backup has %d", ErrRestoreMismatch, table.Name, count, table.RowCount)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// readBackupManifest reads and validates the manifest, which must be the first entry.
+func readBackupManifest(tr *tar.Reader) (*BackupManifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading manifest: %v", ErrBackupCorrupt, err)
+	}
+	if hdr.Name != backupManifestName {
+		return nil, fmt.Errorf("%w: first entry is %q, expected %q", ErrBackupCorrupt, hdr.Name, backupManifestName)
+	}
+	var manifest BackupManifest
+	if err := json.NewDecoder(io.LimitReader(tr, maxBackupSchemaBytes)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: decoding manifest: %v", ErrBackupCorrupt, err)
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > backupFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported backup format version %d", ErrBackupCorrupt, manifest.FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// prepareRestoreTarget creates targetDB or, if it exists and overwrite is set, deletes
+// the tables of the backup from it.
+func (s *DatabaseService) prepareRestoreTarget(ctx context.Context, targetDB string, manifest *BackupManifest, overwrite bool) error {
+	err := s.Create(ctx, targetDB)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrDatabaseExists) || !overwrite {
+		return fmt.Errorf("restore: creating database %q: %w", targetDB, err)
+	}
+
+	existing, err := s.client.Tables.List(ctx, targetDB)
+	if err != nil {
+		return fmt.Errorf("restore: listing tables of %q: %w", targetDB, err)
+	}
+	present := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		present[name] = true
+	}
+	for _, table := range manifest.Tables {
+		if !present[table.Name] {
+			continue
+		}
+		if err := s.client.Tables.Delete(ctx, targetDB, table.Name); err != nil {
+			return fmt.Errorf("restore: deleting existing table %q: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// restoreSchema verifies a schema entry and defines the table in targetDB.
+func (s *DatabaseService) restoreSchema(ctx context.Context, targetDB string, table *BackupTable, r io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(r, maxBackupSchemaBytes))
+	if err != nil {
+		return fmt.Errorf("%w: reading schema of table %q: %v", ErrBackupCorrupt, table.Name, err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != table.SchemaSHA256 {
+		return fmt.Errorf("%w: checksum mismatch in schema of table %q", ErrBackupCorrupt, table.Name)
+	}
+	var schema SchemaPayload
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("%w: decoding schema of table %q: %v", ErrBackupCorrupt, table.Name, err)
+	}
+	schema.TableName = table.Name
+	if err := s.DefineSchema(ctx, targetDB, schema); err != nil {
+		return fmt.Errorf("restore: defining table %q: %w", table.Name, err)
+	}
+	return nil
+}
+
+// restoreRows spools a rows entry to a temporary file while verifying its checksum, then
+// inserts the records into targetDB in batches.
+func (s *DatabaseService) restoreRows(ctx context.Context, targetDB string, table *BackupTable, r io.Reader, batchSize int) error {
+	spool, err := os.CreateTemp("", "nebula-restore-*.ndjson")
+	if err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(spool, hash), r); err != nil {
+		return fmt.Errorf("%w: reading rows of table %q: %v", ErrBackupCorrupt, table.Name, err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != table.RowsSHA256 {
+		return fmt.Errorf("%w: checksum mismatch in rows of table %q", ErrBackupCorrupt, table.Name)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(spool))
+	batch := make([]Record, 0, batchSize)
+	perRow := false // Set when the server has no transaction endpoint
+	var rowCount int64
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		var err error
+		perRow, err = s.insertRows(ctx, targetDB, table.Name, batch, perRow)
+		if err != nil {
+			return fmt.Errorf("restore: loading rows of table %q: %w", table.Name, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for dec.More() {
+		var record Record
+		if err := dec.Decode(&record); err != nil {
+			return fmt.Errorf("%w: decoding rows of table %q: %v", ErrBackupCorrupt, table.Name, err)
+		}
+		rowCount++
+		batch = append(batch, record)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if rowCount != table.RowCount {
+		return fmt.Errorf("%w: table %q has %d rows in the archive, manifest says %d", ErrBackupCorrupt, table.Name, rowCount, table.RowCount)
+	}
+	return nil
+}
+
+// countRows returns the number of rows in dbName.tableName, counting them from a Stream
+// if the server has no count endpoint.
+func (s *DatabaseService) countRows(ctx context.Context, dbName, tableName string) (int64, error) {
+	count, err := s.client.Records.Count(ctx, dbName, tableName, nil)
+	if !errors.Is(err, ErrUnsupportedByServer) {
+		return count, err
+	}
+	count = 0
+	err = s.client.Records.Stream(ctx, dbName, tableName, nil, func(Record) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// insertRows inserts records (keeping their ids) in one transaction, or one by one if
+// perRow is set or the server turns out to have no transaction endpoint. It returns the
+// updated perRow.
+func (s *DatabaseService) insertRows(ctx context.Context, dbName, tableName string, records []Record, perRow bool) (bool, error) {
+	if !perRow {
+		err := s.client.Transaction(ctx, dbName, func(tx *Tx) error {
+			for _, record := range records {
+				tx.Create(tableName, record)
+			}
+			return nil
+		})
+		if !errors.Is(err, ErrUnsupportedByServer) {
+			return false, err
+		}
+	}
+	for _, record := range records {
+		if _, err := s.client.Records.Create(ctx, dbName, tableName, record); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// writeTarEntry writes a regular file entry of size bytes read from r.
+func writeTarEntry(tw *tar.Writer, name string, modTime time.Time, size int64, r io.Reader) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: modTime,
+		Format:  tar.FormatPAX, // Portable; allows long names and large files
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("backup: writing archive: %w", err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("backup: writing archive: %w", err)
+	}
+	return nil
+}
+
+// copySpoolToTar writes the temporary file at path as an archive entry.
+func copySpoolToTar(tw *tar.Writer, name string, modTime time.Time, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	return writeTarEntry(tw, name, modTime, info.Size(), f)
+}
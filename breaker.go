@@ -0,0 +1,207 @@
+// breaker.go
+package nebula
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5                // Consecutive failures that open the circuit
+	defaultBreakerOpenTimeout      = 30 * time.Second // Time the circuit stays open before probing
+	defaultBreakerHalfOpenProbes   = 1                // Successful probes that close the circuit
+)
+
+// CircuitState is the state of a client's circuit breaker (see WithCircuitBreaker).
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Requests flow normally
+	CircuitOpen                         // Requests fail fast with ErrCircuitOpen
+	CircuitHalfOpen                     // A limited number of probe requests test the server
+)
+
+// String returns the state's name ("closed", "open" or "half-open").
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures WithCircuitBreaker. Zero values select the defaults.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive transport errors or 5xx responses that open the circuit (default 5)
+	OpenTimeout      time.Duration // How long the circuit stays open before half-opening (default 30s)
+	HalfOpenProbes   int           // Probe requests allowed while half-open; all must succeed to close (default 1)
+
+	// OnStateChange, if set, is called after every state change (e.g., to alert when the
+	// circuit opens). It runs synchronously on the goroutine of the request that caused
+	// the change and must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// ErrCircuitOpen is returned without contacting the server while the circuit breaker
+// is open. It is temporary (see IsTemporary), and RetryAfter reports when the breaker
+// will next let a probe through.
+var ErrCircuitOpen = errors.New("circuit breaker is open: Nebula is failing, request not sent")
+
+// circuitOpenError is the error returned while the circuit is open.
+type circuitOpenError struct {
+	retryIn time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	if e.retryIn <= 0 {
+		return ErrCircuitOpen.Error() + " (probe in progress)"
+	}
+	return fmt.Sprintf("%s (next probe in %v)", ErrCircuitOpen.Error(), e.retryIn.Round(time.Millisecond))
+}
+func (e *circuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// CircuitState returns the current state of the client's circuit breaker, or
+// CircuitClosed if it has none.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	if c.breaker.state == CircuitOpen && !time.Now().Before(c.breaker.openUntil) {
+		return CircuitHalfOpen // Due to half-open on the next request
+	}
+	return c.breaker.state
+}
+
+// circuitBreaker tracks consecutive failures and fails requests fast while the server
+// appears to be down.
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int       // Consecutive failures while closed
+	openUntil time.Time // When an open circuit half-opens
+	probes    int       // Probes in flight while half-open
+	successes int       // Successful probes while half-open
+}
+
+// newCircuitBreaker creates a closed circuitBreaker, applying defaults to cfg.
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = defaultBreakerHalfOpenProbes
+	}
+	return &circuitBreaker{cfg: cfg}
+}
+
+// allow reports whether a request may be sent. If it returns nil, the caller must
+// report the request's outcome with record, passing whether it was a probe.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	now := time.Now()
+	var from CircuitState
+	changed := false
+	if b.state == CircuitOpen {
+		if now.Before(b.openUntil) {
+			retryIn := b.openUntil.Sub(now)
+			b.mu.Unlock()
+			return false, &circuitOpenError{retryIn: retryIn}
+		}
+		from, changed = b.transition(CircuitHalfOpen, now)
+	}
+	if b.state == CircuitHalfOpen {
+		if b.probes+b.successes >= b.cfg.HalfOpenProbes {
+			b.mu.Unlock()
+			return false, &circuitOpenError{} // Enough probes already; wait for their outcome
+		}
+		b.probes++
+		probe = true
+	}
+	b.mu.Unlock()
+	if changed {
+		b.notify(from, CircuitHalfOpen)
+	}
+	return probe, nil
+}
+
+// record reports the outcome of a request let through by allow: err is the error from
+// sending it, statusCode its response status (0 if none). Context cancellation counts
+// as neither success nor failure.
+func (b *circuitBreaker) record(probe bool, statusCode int, err error) {
+	failed := statusCode >= 500
+	if err != nil && statusCode == 0 {
+		var tErr *transportError
+		if !errors.As(err, &tErr) {
+			// Cancelled by the caller: no verdict on the server, free the probe slot
+			if probe {
+				b.mu.Lock()
+				if b.state == CircuitHalfOpen && b.probes > 0 {
+					b.probes--
+				}
+				b.mu.Unlock()
+			}
+			return
+		}
+		failed = true
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	var from, to CircuitState
+	changed := false
+	switch {
+	case b.state == CircuitHalfOpen && probe:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if failed {
+			to = CircuitOpen
+			from, changed = b.transition(to, now)
+		} else if b.successes++; b.successes >= b.cfg.HalfOpenProbes {
+			to = CircuitClosed
+			from, changed = b.transition(to, now)
+		}
+	case b.state == CircuitClosed:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= b.cfg.FailureThreshold {
+			to = CircuitOpen
+			from, changed = b.transition(to, now)
+		}
+	}
+	b.mu.Unlock()
+	if changed {
+		b.notify(from, to)
+	}
+}
+
+// transition moves the breaker to state, resetting its counters. b.mu must be held.
+func (b *circuitBreaker) transition(state CircuitState, now time.Time) (from CircuitState, changed bool) {
+	from = b.state
+	b.state = state
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if state == CircuitOpen {
+		b.openUntil = now.Add(b.cfg.OpenTimeout)
+	}
+	return from, from != state
+}
+
+// notify calls the OnStateChange callback, if any. b.mu must not be held.
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,137 @@
+// breaker_test.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var status, hits atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte(`{"tables": [], "error": "unavailable"}`))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var changes []string
+	client, err := NewClient(srv.URL, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      30 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			mu.Lock()
+			changes = append(changes, from.String()+">"+to.String())
+			mu.Unlock()
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	call := func() error {
+		_, err := client.Tables.List(context.Background(), "shop")
+		return err
+	}
+	expectState := func(want CircuitState) {
+		t.Helper()
+		if got := client.CircuitState(); got != want {
+			t.Fatalf("state = %v, want %v", got, want)
+		}
+	}
+
+	// Client errors and interleaved successes don't open the circuit
+	status.Store(http.StatusBadRequest)
+	for range 3 {
+		call()
+	}
+	status.Store(http.StatusInternalServerError)
+	call()
+	status.Store(http.StatusOK)
+	call()
+	status.Store(http.StatusInternalServerError)
+	call()
+	expectState(CircuitClosed)
+
+	// A second consecutive failure opens it; requests then fail fast
+	call()
+	expectState(CircuitOpen)
+	before := hits.Load()
+	err = call()
+	if !errors.Is(err, ErrCircuitOpen) || !IsTemporary(err) {
+		t.Errorf("request while open = %v, want a temporary ErrCircuitOpen", err)
+	}
+	if hits.Load() != before {
+		t.Error("request reached the server while the circuit was open")
+	}
+
+	// After the timeout a failing probe reopens it, a successful one closes it
+	time.Sleep(40 * time.Millisecond)
+	expectState(CircuitHalfOpen)
+	call()
+	expectState(CircuitOpen)
+	time.Sleep(40 * time.Millisecond)
+	status.Store(http.StatusOK)
+	if err := call(); err != nil {
+		t.Fatalf("probe = %v", err)
+	}
+	expectState(CircuitClosed)
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreakerProbes(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Millisecond, HalfOpenProbes: 2})
+	b.record(false, http.StatusBadGateway, nil)
+	time.Sleep(2 * time.Millisecond)
+
+	// Only HalfOpenProbes requests are let through while half-open
+	first, err1 := b.allow()
+	second, err2 := b.allow()
+	if !first || !second || err1 != nil || err2 != nil {
+		t.Fatalf("probes = %v %v, %v %v", first, second, err1, err2)
+	}
+	if _, err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third request while half-open = %v, want ErrCircuitOpen", err)
+	}
+
+	// A cancelled probe frees its slot without a verdict
+	b.record(true, 0, context.Canceled)
+	if b.state != CircuitHalfOpen {
+		t.Fatalf("state after a cancelled probe = %v", b.state)
+	}
+	third, err := b.allow()
+	if !third || err != nil {
+		t.Fatalf("probe after a cancelled one = %v, %v", third, err)
+	}
+
+	// All probes must succeed to close the circuit
+	b.record(true, http.StatusOK, nil)
+	if b.state != CircuitHalfOpen {
+		t.Fatalf("state after one of two probes = %v", b.state)
+	}
+	b.record(true, http.StatusNoContent, nil)
+	if b.state != CircuitClosed {
+		t.Fatalf("state after both probes = %v", b.state)
+	}
+
+	// Transport errors count as failures
+	b.record(false, 0, &transportError{method: http.MethodGet, err: errors.New("connection refused")})
+	if b.state != CircuitOpen {
+		t.Errorf("state after a transport error = %v, want open", b.state)
+	}
+}
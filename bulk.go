@@ -0,0 +1,113 @@
+// bulk.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// whereOptions holds settings for UpdateWhere and DeleteWhere.
+type whereOptions struct {
+	allRows bool
+}
+
+// WhereOption customises UpdateWhere and DeleteWhere.
+type WhereOption func(*whereOptions)
+
+// AllRows explicitly allows UpdateWhere or DeleteWhere to run with an empty filter,
+// affecting every record in the table. Without it, an empty filter is rejected
+// client-side to prevent accidental table-wide writes.
+func AllRows() WhereOption {
+	return func(o *whereOptions) {
+		o.allRows = true
+	}
+}
+
+// UpdateWhere applies patch to every record matching filter and returns the number
+// of rows affected. filter must be non-empty unless AllRows() is passed. Servers without
+// the bulk_write capability fail with ErrUnsupportedByServer.
+//
+//	n, err := client.Records.UpdateWhere(ctx, "jobs", "runs",
+//		nebula.Filter{"status": "stale"}, map[string]interface{}{"status": "failed"})
+func (s *RecordService) UpdateWhere(ctx context.Context, dbName, tableName string, filter Filter, patch map[string]interface{}, opts ...WhereOption) (int64, error) {
+	if len(patch) == 0 {
+		return 0, errors.New("update data cannot be empty")
+	}
+	if err := validateRecordData(patch).err("Records.UpdateWhere"); err != nil {
+		return 0, err
+	}
+	apiPath, err := s.buildWherePath("Records.UpdateWhere", dbName, tableName, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var result BulkWriteResponse // Expecting {"rows_affected": N}
+	err = s.client.doRequest(ctx, "Records.UpdateWhere", http.MethodPatch, apiPath, patch, &result)
+	if err != nil {
+		// Handles 400 (bad type/col/filter), 401, 404 (db/table not found), 405 (no bulk writes), 409 (constraint), 500
+		if errors.Is(err, ErrNotFound) {
+			return 0, err // The records endpoint exists on every server: the table is missing
+		}
+		return 0, unsupportedByServer("Records.UpdateWhere", CapabilityBulkWrite, err)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteWhere removes every record matching filter and returns the number of rows
+// affected. filter must be non-empty unless AllRows() is passed. Servers without the
+// bulk_write capability fail with ErrUnsupportedByServer.
+func (s *RecordService) DeleteWhere(ctx context.Context, dbName, tableName string, filter Filter, opts ...WhereOption) (int64, error) {
+	apiPath, err := s.buildWherePath("Records.DeleteWhere", dbName, tableName, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var result BulkWriteResponse // Expecting {"rows_affected": N}
+	err = s.client.doRequest(ctx, "Records.DeleteWhere", http.MethodDelete, apiPath, nil, &result)
+	if err != nil {
+		// Handles 400 (bad filter), 401, 404 (db/table not found), 405 (no bulk writes), 500
+		if errors.Is(err, ErrNotFound) {
+			return 0, err // The records endpoint exists on every server: the table is missing
+		}
+		return 0, unsupportedByServer("Records.DeleteWhere", CapabilityBulkWrite, err)
+	}
+	return result.RowsAffected, nil
+}
+
+// buildWherePath constructs the records path with the filter query for a bulk write,
+// enforcing the non-empty filter guard.
+func (s *RecordService) buildWherePath(op, dbName, tableName string, filter Filter, opts []WhereOption) (string, error) {
+	var wo whereOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&wo)
+		}
+	}
+
+	apiPath, err := s.buildRecordPath(op, dbName, tableName)
+	if err != nil {
+		return "", err
+	}
+	if err := s.client.requireCapability(op, CapabilityBulkWrite); err != nil {
+		return "", err
+	}
+
+	queryValues := url.Values{}
+	if err := addFilterQuery(op, queryValues, filter); err != nil {
+		return "", err
+	}
+	if len(queryValues) == 0 {
+		if !wo.allRows {
+			return "", (fieldErrors{{
+				Field:   "filter",
+				Code:    FieldCodeRequired,
+				Message: "filter cannot be empty; pass nebula.AllRows() to affect every record",
+			}}).err(op)
+		}
+		queryValues.Set("all_rows", "true") // Tell the server the table-wide write is intended
+	}
+	return fmt.Sprintf("%s?%s", apiPath, queryValues.Encode()), nil
+}
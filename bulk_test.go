@@ -0,0 +1,48 @@
+// bulk_test.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReservedFilterKeysRejected(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"rows_affected": 1, "count": 1}`))
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	ctx := context.Background()
+
+	for _, key := range []string{"all_rows", "limit", "offset", "sort"} {
+		filter := Filter{key: "1"}
+		calls := map[string]error{}
+		_, calls["DeleteWhere"] = client.Records.DeleteWhere(ctx, "db", "t", filter)
+		_, calls["UpdateWhere"] = client.Records.UpdateWhere(ctx, "db", "t", filter, map[string]interface{}{"x": 1})
+		_, calls["Count"] = client.Records.Count(ctx, "db", "t", filter)
+		_, calls["List"] = client.Records.List(ctx, "db", "t", &ListRecordsOptions{Filters: filter})
+		calls["Stream"] = client.Records.Stream(ctx, "db", "t", &ListRecordsOptions{Filters: filter}, func(Record) error { return nil })
+		for name, err := range calls {
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("%s with filter key %q: err = %v, want ErrValidation", name, key, err)
+			}
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d requests sent, want none", n)
+	}
+
+	if _, err := client.Records.DeleteWhere(ctx, "db", "t", Filter{"status": "stale"}); err != nil {
+		t.Errorf("DeleteWhere with an ordinary filter: %v", err)
+	}
+}
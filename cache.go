@@ -0,0 +1,238 @@
+// cache.go
+package nebula
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheEntries = 1000            // Capacity of the default cache (see WithCache)
+	defaultCacheTTL     = 5 * time.Minute // Lifetime of entries in the default cache
+)
+
+// CachedResponse is a successful GET response stored by a Cache, along with the
+// validators used to revalidate it. Entries are shared and must not be modified.
+type CachedResponse struct {
+	Body         []byte      // Raw JSON response body
+	Header       http.Header // Response headers (e.g., ETag, as returned by GetWithVersion)
+	ETag         string      // Sent as If-None-Match on revalidation
+	LastModified string      // Sent as If-Modified-Since on revalidation
+}
+
+// Cache stores GET responses for a Client (see WithCache). Keys are request URLs
+// (including the query string), followed for authenticated requests by '#' and a digest
+// of the auth token, so users sharing a cache never see each other's responses.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry stored under key, if present and not expired.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores (or replaces) the entry under key and restarts its lifetime.
+	Set(key string, resp *CachedResponse)
+	// Invalidate removes the entry under prefix and every entry below it: keys that
+	// continue prefix with '/', '?' or '#' (the latter for every auth identity).
+	Invalidate(prefix string)
+}
+
+// NewLRUCache returns an in-memory Cache holding up to capacity entries, evicting the
+// least recently used when full. Entries expire ttl after they were last stored or
+// revalidated; a ttl <= 0 means they only leave through eviction or invalidation.
+// A capacity <= 0 selects the default of 1000 entries.
+func NewLRUCache(capacity int, ttl time.Duration) Cache {
+	if capacity <= 0 {
+		capacity = defaultCacheEntries
+	}
+	return &lruCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// lruCache is the Cache returned by NewLRUCache.
+type lruCache struct {
+	capacity int
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	order *list.List               // Most recently used at the front
+	items map[string]*list.Element // Values are *lruItem
+}
+
+// lruItem is one entry of an lruCache.
+type lruItem struct {
+	key     string
+	resp    *CachedResponse
+	expires time.Time // Zero if the entry doesn't expire
+}
+
+// Get implements Cache.
+func (c *lruCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*lruItem)
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return item.resp, true
+}
+
+// Set implements Cache.
+func (c *lruCache) Set(key string, resp *CachedResponse) {
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*lruItem)
+		item.resp, item.expires = resp, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, resp: resp, expires: expires})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Invalidate implements Cache.
+func (c *lruCache) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.items {
+		if keyWithin(key, prefix) {
+			c.remove(el)
+		}
+	}
+}
+
+// remove deletes el from the cache. c.mu must be held.
+func (c *lruCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruItem).key)
+}
+
+// keyWithin reports whether key is prefix or lies below it (see Cache.Invalidate).
+func keyWithin(key, prefix string) bool {
+	if !strings.HasPrefix(key, prefix) {
+		return false
+	}
+	rest := key[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?' || rest[0] == '#'
+}
+
+// cacheKey returns the cache key for a GET of apiPath: its URL, scoped to the current
+// auth token (see Cache).
+func (c *Client) cacheKey(apiPath string) string {
+	key := c.resolveURL(apiPath)
+	if token := c.authToken; token != "" {
+		sum := sha256.Sum256([]byte(token))
+		key += "#" + hex.EncodeToString(sum[:16])
+	}
+	return key
+}
+
+// doCachedRequest performs a GET through the client's cache: a cached response is
+// revalidated with a conditional request and reused if the server answers 304 Not
+// Modified. Fresh 200 responses carrying an ETag or Last-Modified header are stored.
+func (c *Client) doCachedRequest(ctx context.Context, op, apiPath string, responseBody interface{}, ro requestOptions) error {
+	key := c.cacheKey(apiPath)
+	cached, hit := c.cache.Get(key)
+	if hit {
+		header := make(http.Header, len(ro.header)+2)
+		for k, v := range ro.header {
+			header[k] = v // Copy: ro.header may be shared with the caller's options
+		}
+		if cached.ETag != "" {
+			header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			header.Set("If-Modified-Since", cached.LastModified)
+		}
+		ro.header = header
+	}
+
+	resp, err := c.send(ctx, op, http.MethodGet, apiPath, nil, ro)
+	if err != nil {
+		var apiErr *APIError
+		if hit && errors.As(err, &apiErr) {
+			c.cache.Invalidate(key) // The resource is gone or no longer readable
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	header := resp.Header
+	switch {
+	case hit && resp.StatusCode == http.StatusNotModified:
+		body, header = cached.Body, cached.Header
+		c.cache.Set(key, cached) // Restart the entry's lifetime
+	case resp.StatusCode == http.StatusNoContent:
+	default:
+		body, err = io.ReadAll(limitBody(resp.Body, ro.maxResponseBytes))
+		if err != nil {
+			return decodeError(err)
+		}
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if resp.StatusCode == http.StatusOK && (etag != "" || lastModified != "") {
+			c.cache.Set(key, &CachedResponse{Body: body, Header: resp.Header.Clone(), ETag: etag, LastModified: lastModified})
+		}
+	}
+	if ro.responseHeader != nil {
+		*ro.responseHeader = header
+	}
+
+	if len(body) == 0 && resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(responseBody); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+// invalidateCache drops the cached responses a write to apiPath may have made stale:
+// everything under the table for record and other table sub-resource writes, everything
+// under the database for table, schema and transaction writes, and every cached
+// database response for database creation and deletion. Entries of every auth identity
+// are dropped, since they all see the same data.
+func (c *Client) invalidateCache(apiPath string) {
+	if c.cache == nil {
+		return
+	}
+	p, _, _ := strings.Cut(apiPath, "?")
+	p = strings.TrimPrefix(strings.Trim(p, "/"), strings.Trim(apiVersionPath, "/"))
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	if segs[0] != "databases" {
+		return // Auth endpoints don't touch cached data
+	}
+	switch {
+	case len(segs) > 4 && segs[2] == "tables":
+		segs = segs[:4]
+	case len(segs) > 2:
+		segs = segs[:2]
+	default:
+		segs = segs[:1]
+	}
+	c.cache.Invalidate(c.resolveURL(c.getAPIPath(strings.Join(segs, "/"))))
+}
@@ -0,0 +1,162 @@
+// cache_test.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2, 0)
+	a, b, d := &CachedResponse{ETag: "a"}, &CachedResponse{ETag: "b"}, &CachedResponse{ETag: "d"}
+	c.Set("a", a)
+	c.Set("b", b)
+	c.Get("a") // Now b is the least recently used
+	c.Set("d", d)
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if got, ok := c.Get("a"); !ok || got != a {
+		t.Errorf("Get(a) = %v, %v", got, ok)
+	}
+
+	expiring := NewLRUCache(0, time.Millisecond)
+	expiring.Set("a", a)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.Get("a"); ok {
+		t.Error("expired entry was returned")
+	}
+
+	const table = "http://h/api/v1/databases/shop/tables/orders"
+	keys := []string{table, table + "#abc", table + "?limit=1", table + "/records/1", table + "s", table + "_old"}
+	c = NewLRUCache(10, 0)
+	for _, key := range keys {
+		c.Set(key, a)
+	}
+	c.Invalidate(table)
+	for i, key := range keys {
+		if _, ok := c.Get(key); ok != (i >= 4) {
+			t.Errorf("after Invalidate(%q): %q present = %v", table, key, ok)
+		}
+	}
+}
+
+// versionedServer serves records whose ETag is their version, answering matching
+// If-None-Match requests with 304.
+type versionedServer struct {
+	mu          sync.Mutex
+	versions    map[string]int // Keyed by URL path
+	conditional int            // Requests carrying If-None-Match
+	notModified int            // 304 responses sent
+}
+
+func (s *versionedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method != http.MethodGet {
+		s.versions[r.URL.Path]++
+		s.versions[r.URL.Path[:strings.LastIndex(r.URL.Path, "/")]]++ // The record list
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	version := s.versions[r.URL.Path]
+	etag := fmt.Sprintf(`"v%d"`, version)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		s.conditional++
+		if inm == etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("ETag", etag)
+	if strings.HasSuffix(r.URL.Path, "/records") {
+		fmt.Fprintf(w, `[{"id": 1, "version": %d}]`, version)
+		return
+	}
+	fmt.Fprintf(w, `{"id": 1, "version": %d}`, version)
+}
+
+func (s *versionedServer) counts() (conditional, notModified int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conditional, s.notModified
+}
+
+func TestCachedRequests(t *testing.T) {
+	backend := &versionedServer{versions: map[string]int{}}
+	srv := httptest.NewServer(backend)
+	defer srv.Close()
+	client, err := NewClient(srv.URL, WithCache(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("alice")
+	ctx := context.Background()
+
+	version := func() int64 {
+		t.Helper()
+		rec, err := client.Records.Get(ctx, "shop", "orders", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := rec.Int64("version")
+		return v
+	}
+
+	// Revalidation: the second read is answered by a 304 and served from the cache
+	if v := version(); v != 0 {
+		t.Fatalf("first Get: version %d", v)
+	}
+	if v := version(); v != 0 {
+		t.Errorf("revalidated Get: version %d, want 0", v)
+	}
+	if cond, nm := backend.counts(); cond != 1 || nm != 1 {
+		t.Errorf("after revalidation: %d conditional requests, %d 304s; want 1, 1", cond, nm)
+	}
+
+	// A change made elsewhere fails revalidation and is picked up
+	backend.mu.Lock()
+	backend.versions["/api/v1/databases/shop/tables/orders/records/1"] = 5
+	backend.mu.Unlock()
+	if v := version(); v != 5 {
+		t.Errorf("Get after an external change: version %d, want 5", v)
+	}
+	if cond, nm := backend.counts(); cond != 2 || nm != 1 {
+		t.Errorf("after an external change: %d conditional requests, %d 304s; want 2, 1", cond, nm)
+	}
+
+	// Writes through the client drop the record and the table's lists
+	if _, err := client.Records.List(ctx, "shop", "orders", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Records.Update(ctx, "shop", "orders", 1, map[string]interface{}{"note": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := version(); v != 6 {
+		t.Errorf("Get after Update: version %d, want 6", v)
+	}
+	recs, err := client.Records.List(ctx, "shop", "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := recs[0].Int64("version"); v != 1 {
+		t.Errorf("List after Update: version %d, want 1", v)
+	}
+	if cond, _ := backend.counts(); cond != 2 {
+		t.Errorf("after Update: %d conditional requests, want 2 (entries not invalidated)", cond)
+	}
+
+	// Entries are scoped to the auth token
+	client.SetAuthToken("bob")
+	version()
+	if cond, _ := backend.counts(); cond != 2 {
+		t.Errorf("another token revalidated %d times, want none", cond-2)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +21,17 @@ type Client struct {
 	httpClient *http.Client // HTTP client for making requests
 	authToken  string       // Internal storage for JWT (set after Login)
 
+	maxResponseBytes int64               // Default limit for successful response bodies (0 = unlimited)
+	cache            Cache               // GET response cache (nil = disabled, see WithCache)
+	limiter          *rateLimiter        // Client-side rate limit (nil = disabled, see WithRateLimit)
+	concurrency      *concurrencyLimiter // Cap on requests in flight (nil = disabled, see WithMaxConcurrentRequests)
+	breaker          *circuitBreaker     // Fails fast while Nebula is down (nil = disabled, see WithCircuitBreaker)
+	endpoints        *endpointSet        // Replicas including baseURL (nil = baseURL only, see WithEndpoints)
+	schemas          *schemaCache        // Schemas for typing records (nil = disabled, see WithTypedRecords)
+
+	infoMu sync.Mutex
+	info   *ServerInfo // Last ServerInfo result, used to gate optional features (nil = unknown)
+
 	// Services - Initialized in NewClient, provide access to grouped API methods
 	Auth      AuthService
 	Databases DatabaseService
@@ -30,25 +42,20 @@ type Client struct {
 
 // NewClient creates a new Nebula BaaS API client.
 // baseURL is the base address of your Nebula instance (e.g., "http://localhost:8080", "https://api.yourdomain.com").
+// It may include a path prefix (e.g., "https://proxy.example.com/nebula/") when Nebula is served behind a reverse proxy.
+// Additional replicas can be added with WithEndpoints.
 // opts are functional options to customize the client (e.g., WithHTTPClient, WithRequestTimeout).
 func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
 	// 1. Validate and parse Base URL
-	if baseURL == "" {
-		return nil, fmt.Errorf("baseURL cannot be empty")
-	}
-	// Ensure trailing slash for easy joining, but remove potential multiple slashes
-	baseURL = strings.TrimSuffix(baseURL, "/") + "/"
-	parsedBaseURL, err := url.ParseRequestURI(baseURL)
+	parsedBaseURL, err := parseBaseURL(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid baseURL %q: %w", baseURL, err)
-	}
-	if parsedBaseURL.Scheme != "http" && parsedBaseURL.Scheme != "https" {
-		return nil, fmt.Errorf("invalid baseURL scheme %q: must be http or https", parsedBaseURL.Scheme)
+		return nil, err
 	}
 
 	// 2. Process functional options
 	options := clientOptions{ // Default options
-		requestTimeout: defaultTimeout,
+		requestTimeout:   defaultTimeout,
+		maxResponseBytes: defaultMaxResponseBytes,
 	}
 	for _, opt := range opts {
 		if err := opt(&options); err != nil {
@@ -68,10 +75,27 @@ func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
 
 	// 4. Create the main client struct (initialize base fields)
 	client := &Client{
-		baseURL:    parsedBaseURL,
-		httpClient: httpClient,
+		baseURL:          parsedBaseURL,
+		httpClient:       httpClient,
+		maxResponseBytes: options.maxResponseBytes,
+		cache:            options.cache,
 		// authToken will be set by Login
 	}
+	if options.rateLimit > 0 {
+		client.limiter = newRateLimiter(options.rateLimit, options.rateBurst)
+	}
+	if options.maxConcurrent > 0 {
+		client.concurrency = &concurrencyLimiter{slots: make(chan struct{}, options.maxConcurrent)}
+	}
+	if len(options.endpoints) > 0 {
+		client.endpoints = newEndpointSet(append([]*url.URL{parsedBaseURL}, options.endpoints...), options.endpointPolicy, options.endpointRecheck)
+	}
+	if options.typedRecords {
+		client.schemas = &schemaCache{tables: make(map[string]*SchemaPayload)}
+	}
+	if options.circuitBreaker != nil {
+		client.breaker = newCircuitBreaker(*options.circuitBreaker)
+	}
 
 	// 5. Initialize sub-services, passing the client reference
 	client.Auth = AuthService{client: client}
@@ -85,10 +109,27 @@ func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
 
 // --- Helper methods (could be in request.go later) ---
 
-// getAPIPath constructs the full path for V1 API endpoints.
+// parseBaseURL validates and parses the base address of a Nebula instance.
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("baseURL cannot be empty")
+	}
+	// Ensure trailing slash for easy joining, but remove potential multiple slashes
+	baseURL = strings.TrimSuffix(baseURL, "/") + "/"
+	parsedBaseURL, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseURL %q: %w", baseURL, err)
+	}
+	if parsedBaseURL.Scheme != "http" && parsedBaseURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid baseURL scheme %q: must be http or https", parsedBaseURL.Scheme)
+	}
+	return parsedBaseURL, nil
+}
+
+// getAPIPath constructs the full path for V1 API endpoints from an already-escaped subPath.
+// Use apiPath (paths.go) to build paths containing user-supplied names.
 func (c *Client) getAPIPath(subPath string) string {
 	// Ensure subPath doesn't start with / if apiVersionPath ends with /
-	// Use path.Join or url.JoinPath for robust path joining later if needed.
 	return apiVersionPath + "/" + strings.TrimPrefix(subPath, "/")
 }
 
@@ -4,13 +4,24 @@ package nebula
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 // clientOptions holds internal configuration settings for the Client.
 type clientOptions struct {
-	httpClient     *http.Client
-	requestTimeout time.Duration
+	httpClient       *http.Client
+	requestTimeout   time.Duration
+	maxResponseBytes int64
+	cache            Cache
+	rateLimit        float64 // Requests per second (0 = unlimited)
+	rateBurst        int
+	maxConcurrent    int                   // 0 = unlimited
+	circuitBreaker   *CircuitBreakerConfig // nil = no circuit breaker
+	endpoints        []*url.URL            // Replicas in addition to the base URL
+	endpointPolicy   EndpointPolicy
+	endpointRecheck  time.Duration
+	typedRecords     bool
 	// Add other options like custom logger, retry policy, etc. here
 }
 
@@ -40,3 +51,215 @@ func WithRequestTimeout(d time.Duration) ClientOption {
 		return nil
 	}
 }
+
+// WithMaxResponseBytes sets the maximum size of a successful response body the client
+// will decode. Larger responses fail with ErrResponseTooLarge. Use 0 to disable the limit.
+// Defaults to 32MB. Individual calls can override it with MaxResponseBytes.
+func WithMaxResponseBytes(n int64) ClientOption {
+	return func(o *clientOptions) error {
+		if n < 0 {
+			return fmt.Errorf("max response bytes cannot be negative")
+		}
+		o.maxResponseBytes = n
+		return nil
+	}
+}
+
+// WithCache enables a client-side cache for GET requests. Cached responses are
+// revalidated on every read with a conditional request (If-None-Match/If-Modified-Since)
+// and reused when the server answers 304 Not Modified; only responses carrying an ETag
+// or Last-Modified header are cached. Writes made through the same client invalidate
+// the affected record, table or database entries.
+// A nil cache selects NewLRUCache(1000, 5*time.Minute).
+func WithCache(cache Cache) ClientOption {
+	return func(o *clientOptions) error {
+		if cache == nil {
+			cache = NewLRUCache(defaultCacheEntries, defaultCacheTTL)
+		}
+		o.cache = cache
+		return nil
+	}
+}
+
+// WithTypedRecords makes RecordService reads (Get, List, Stream, GetMany and the
+// records they back) convert values by the table schema rather than by their JSON form:
+// INTEGER to int64, REAL to float64, BOOLEAN to bool and BLOB to []byte (see
+// Record.ApplySchema). Each table's schema is fetched once and remembered until a schema,
+// table or database write through the client.
+func WithTypedRecords() ClientOption {
+	return func(o *clientOptions) error {
+		o.typedRecords = true
+		return nil
+	}
+}
+
+// WithRateLimit limits the client to rps requests per second on average, allowing
+// bursts of up to burst requests. Requests over the limit wait (honouring their context)
+// instead of failing; a request whose context deadline is too close to wait fails at once.
+// When the server still answers 429 Too Many Requests, the client halves its rate and
+// pauses for the Retry-After delay, then gradually returns to rps as requests succeed.
+// Waiting is reported by Client.ThrottleStats.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(o *clientOptions) error {
+		if rps <= 0 {
+			return fmt.Errorf("rate limit must be positive")
+		}
+		if burst < 1 {
+			return fmt.Errorf("rate limit burst must be at least 1")
+		}
+		o.rateLimit = rps
+		o.rateBurst = burst
+		return nil
+	}
+}
+
+// WithMaxConcurrentRequests caps the number of API calls in flight at n; further calls
+// wait (honouring their context) until one completes. Long-lived streams (Stream, Watch)
+// don't count against the cap. Waiting is reported by Client.ThrottleStats.
+func WithMaxConcurrentRequests(n int) ClientOption {
+	return func(o *clientOptions) error {
+		if n <= 0 {
+			return fmt.Errorf("max concurrent requests must be positive")
+		}
+		o.maxConcurrent = n
+		return nil
+	}
+}
+
+// WithCircuitBreaker stops the client from sending requests while Nebula appears to
+// be down. After cfg.FailureThreshold consecutive transport errors or 5xx responses the
+// circuit opens and calls fail immediately with ErrCircuitOpen instead of waiting for
+// the request timeout. After cfg.OpenTimeout the circuit half-opens and lets
+// cfg.HalfOpenProbes requests through: if they all succeed it closes, otherwise it opens
+// again. Other 4xx responses count as successes: the server is answering.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) ClientOption {
+	return func(o *clientOptions) error {
+		if cfg.FailureThreshold < 0 || cfg.OpenTimeout < 0 || cfg.HalfOpenProbes < 0 {
+			return fmt.Errorf("circuit breaker settings cannot be negative")
+		}
+		o.circuitBreaker = &cfg
+		return nil
+	}
+}
+
+// WithEndpoints adds replicas of the Nebula instance at the given base URLs. Requests go
+// to the NewClient base URL or a replica as chosen by the endpoint policy (see
+// WithEndpointPolicy; by default the base URL, failing over to the replicas in order).
+// An endpoint that fails with a transport error is marked unhealthy and skipped until
+// it is re-probed (see WithEndpointRecheck). The request is retried on the next
+// endpoint if that is safe: always for GET, PUT and DELETE without IfMatch, and for
+// other requests only if the connection could not be established. There is no
+// background health check: an unhealthy endpoint is only probed again by live traffic,
+// the first request routed to it after the recheck interval. The auth token is shared
+// by all endpoints, so they must accept the same tokens.
+func WithEndpoints(baseURLs ...string) ClientOption {
+	return func(o *clientOptions) error {
+		for _, baseURL := range baseURLs {
+			u, err := parseBaseURL(baseURL)
+			if err != nil {
+				return err
+			}
+			o.endpoints = append(o.endpoints, u)
+		}
+		return nil
+	}
+}
+
+// WithEndpointPolicy sets how requests are spread over the endpoints added with
+// WithEndpoints. Defaults to EndpointFailover.
+func WithEndpointPolicy(policy EndpointPolicy) ClientOption {
+	return func(o *clientOptions) error {
+		switch policy {
+		case EndpointFailover, EndpointRoundRobin, EndpointLeastLatency:
+		default:
+			return fmt.Errorf("unknown endpoint policy %d", int(policy))
+		}
+		o.endpointPolicy = policy
+		return nil
+	}
+}
+
+// WithEndpointRecheck sets how long an unhealthy endpoint is skipped before a request
+// is sent to it again to probe whether it has recovered. Defaults to 30s.
+func WithEndpointRecheck(d time.Duration) ClientOption {
+	return func(o *clientOptions) error {
+		if d <= 0 {
+			return fmt.Errorf("endpoint recheck interval must be positive")
+		}
+		o.endpointRecheck = d
+		return nil
+	}
+}
+
+// requestOptions holds per-call settings, initialised from the client defaults.
+type requestOptions struct {
+	maxResponseBytes int64
+	header           http.Header  // Extra request headers (e.g., If-Match)
+	responseHeader   *http.Header // If set, receives the success response headers (e.g., ETag)
+	longLived        bool         // Long-lived stream: ignore the http.Client timeout, rely on ctx
+	readOnly         bool         // Non-GET call that changes nothing: keep cached responses
+}
+
+// RequestOption customises a single API call (e.g., client.Records.List(ctx, db, table, nil, nebula.MaxResponseBytes(n))).
+type RequestOption func(*requestOptions)
+
+// MaxResponseBytes overrides the client's response size limit (see WithMaxResponseBytes)
+// for a single call. Use 0 to disable the limit for that call.
+func MaxResponseBytes(n int64) RequestOption {
+	return func(o *requestOptions) {
+		if n >= 0 {
+			o.maxResponseBytes = n
+		}
+	}
+}
+
+// IfMatch makes an Update or Delete conditional on the record still having the given
+// version (as returned by RecordService.GetWithVersion). It is sent as the If-Match header;
+// if the record changed in the meantime the call fails with ErrPreconditionFailed.
+func IfMatch(version string) RequestOption {
+	return withHeader("If-Match", version)
+}
+
+// withHeader sets an extra request header for a single call.
+func withHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// captureResponseHeader stores the success response headers of a single call in dst.
+func captureResponseHeader(dst *http.Header) RequestOption {
+	return func(o *requestOptions) {
+		o.responseHeader = dst
+	}
+}
+
+// longLivedRequest marks a call as a long-lived stream (e.g., Watch) that must not be
+// cut off by the http.Client's overall timeout; cancellation is left to the context.
+func longLivedRequest() RequestOption {
+	return func(o *requestOptions) {
+		o.longLived = true
+	}
+}
+
+// readOnlyRequest marks a non-GET call (e.g., Aggregate's POST) as a query that doesn't
+// change data, so it doesn't invalidate cached responses.
+func readOnlyRequest() RequestOption {
+	return func(o *requestOptions) {
+		o.readOnly = true
+	}
+}
+
+// requestOptions resolves per-call options against the client defaults.
+func (c *Client) requestOptions(opts []RequestOption) requestOptions {
+	ro := requestOptions{maxResponseBytes: c.maxResponseBytes}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&ro)
+		}
+	}
+	return ro
+}
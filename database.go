@@ -3,10 +3,7 @@ package nebula
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"net/http"
-	"strings" // Import strings
 )
 
 // DatabaseService provides methods for interacting with the /databases endpoints
@@ -17,20 +14,19 @@ type DatabaseService struct {
 
 // Create registers a new logical database for the authenticated user.
 func (s *DatabaseService) Create(ctx context.Context, dbName string) error {
-	if strings.TrimSpace(dbName) == "" {
-		return errors.New("database name cannot be empty") // Basic client validation
+	if err := validateDatabaseName(dbName).err("Databases.Create"); err != nil {
+		return err // Client-side name format validation against the server's naming rules
 	}
-	// Consider adding client-side name format validation? Depends on SDK strictness goal.
 
 	payload := CreateDatabasePayload{
 		DBName: dbName,
 	}
 	apiPath := s.client.getAPIPath("databases") // Use helper for consistency
 
-	err := s.client.doRequest(ctx, http.MethodPost, apiPath, payload, nil)
+	err := s.client.doRequest(ctx, "Databases.Create", http.MethodPost, apiPath, payload, nil)
 	if err != nil {
 		// doRequest maps standard errors (401, 409, 500 etc.)
-		// Map Conflict specifically if desired, though caller can check errors.Is(err, ErrConflict)
+		// A 409 here is mapped to ErrDatabaseExists (which also matches ErrConflict)
 		return err
 	}
 	return nil // Success
@@ -41,7 +37,7 @@ func (s *DatabaseService) List(ctx context.Context) ([]string, error) {
 	apiPath := s.client.getAPIPath("databases")
 	var result ListDatabasesResponse // Expecting {"databases": ["name1", ...]}
 
-	err := s.client.doRequest(ctx, http.MethodGet, apiPath, nil, &result)
+	err := s.client.doRequest(ctx, "Databases.List", http.MethodGet, apiPath, nil, &result)
 	if err != nil {
 		return nil, err // Return error from doRequest (e.g., ErrUnauthorized, ErrInternalServer)
 	}
@@ -55,19 +51,16 @@ func (s *DatabaseService) List(ctx context.Context) ([]string, error) {
 }
 
 // Delete removes a database registration and attempts to delete the associated data file.
-// Returns ErrNotFound if the database registration doesn't exist.
+// Returns ErrDatabaseNotFound (which also matches ErrNotFound) if the database registration doesn't exist.
 func (s *DatabaseService) Delete(ctx context.Context, dbName string) error {
-	if strings.TrimSpace(dbName) == "" {
-		return errors.New("database name cannot be empty")
+	apiPath, err := s.client.databasePath("Databases.Delete", dbName) // Validates and escapes dbName
+	if err != nil {
+		return err
 	}
-	// URL encode dbName? Generally needed if names can contain special chars.
-	// Let's assume simple names for now based on prior validation.
-	// Proper way: use url.PathEscape
-	apiPath := s.client.getAPIPath(fmt.Sprintf("databases/%s", dbName))
 
-	err := s.client.doRequest(ctx, http.MethodDelete, apiPath, nil, nil)
+	err = s.client.doRequest(ctx, "Databases.Delete", http.MethodDelete, apiPath, nil, nil)
 	if err != nil {
-		// doRequest maps 404 to ErrNotFound
+		// doRequest maps 404 to ErrDatabaseNotFound
 		return err
 	}
 	return nil // Success (204 No Content handled by doRequest)
@@ -76,20 +69,17 @@ func (s *DatabaseService) Delete(ctx context.Context, dbName string) error {
 // DefineSchema creates or updates the schema for a table within a specified database.
 // Note: The backend uses CREATE TABLE IF NOT EXISTS, making it somewhat idempotent.
 func (s *DatabaseService) DefineSchema(ctx context.Context, dbName string, schema SchemaPayload) error {
-	if strings.TrimSpace(dbName) == "" {
-		return errors.New("database name cannot be empty")
-	}
-	if strings.TrimSpace(schema.TableName) == "" {
-		return errors.New("table name cannot be empty")
-	}
-	if len(schema.Columns) == 0 {
-		return errors.New("schema must contain at least one column")
+	// Validate table/column names and types client-side for faster, per-field feedback
+	if err := validateSchema(schema).err("Databases.DefineSchema"); err != nil {
+		return err
 	}
-	// Add client-side validation for column names/types if desired for faster feedback
 
-	apiPath := s.client.getAPIPath(fmt.Sprintf("databases/%s/schema", dbName))
+	apiPath, err := s.client.databasePath("Databases.DefineSchema", dbName, "schema")
+	if err != nil {
+		return err
+	}
 
-	err := s.client.doRequest(ctx, http.MethodPost, apiPath, schema, nil)
+	err = s.client.doRequest(ctx, "Databases.DefineSchema", http.MethodPost, apiPath, schema, nil)
 	if err != nil {
 		// doRequest maps standard errors (400, 401, 404, 500)
 		return err
@@ -0,0 +1,218 @@
+// endpoints.go
+package nebula
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEndpointRecheck = 30 * time.Second // How long an unhealthy endpoint is skipped
+	endpointLatencyWeight  = 0.2              // Weight of a new sample in the latency moving average
+)
+
+// EndpointPolicy selects the endpoint serving each request when a client has several
+// (see WithEndpoints).
+type EndpointPolicy int
+
+const (
+	EndpointFailover     EndpointPolicy = iota // The first healthy endpoint: the base URL, then the replicas in order
+	EndpointRoundRobin                         // Healthy endpoints in turn
+	EndpointLeastLatency                       // The healthy endpoint with the lowest average response time
+)
+
+// String returns the policy's name.
+func (p EndpointPolicy) String() string {
+	switch p {
+	case EndpointFailover:
+		return "failover"
+	case EndpointRoundRobin:
+		return "round-robin"
+	case EndpointLeastLatency:
+		return "least-latency"
+	}
+	return fmt.Sprintf("EndpointPolicy(%d)", int(p))
+}
+
+// EndpointStatus describes one of a client's endpoints (see Client.Endpoints).
+type EndpointStatus struct {
+	URL     string        // Base URL
+	Healthy bool          // False after a transport error, until a request succeeds again
+	Latency time.Duration // Moving average time to response headers (0 = not measured yet)
+}
+
+// Endpoints returns the status of the client's endpoints, the base URL first.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return []EndpointStatus{{URL: c.baseURL.String(), Healthy: true}}
+	}
+	s := c.endpoints
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]EndpointStatus, len(s.list))
+	for i, ep := range s.list {
+		statuses[i] = EndpointStatus{URL: ep.baseURL.String(), Healthy: ep.healthy, Latency: ep.latency}
+	}
+	return statuses
+}
+
+// endpoint is one base URL of an endpointSet.
+type endpoint struct {
+	index     int
+	baseURL   *url.URL
+	healthy   bool
+	downUntil time.Time     // When an unhealthy endpoint may be probed again
+	latency   time.Duration // Moving average; 0 until measured
+}
+
+// endpointSet spreads requests over several endpoints according to a policy.
+type endpointSet struct {
+	policy  EndpointPolicy
+	recheck time.Duration
+
+	mu   sync.Mutex
+	list []*endpoint
+	next int // Round-robin position
+}
+
+// newEndpointSet creates an endpointSet with every endpoint healthy.
+func newEndpointSet(baseURLs []*url.URL, policy EndpointPolicy, recheck time.Duration) *endpointSet {
+	if recheck <= 0 {
+		recheck = defaultEndpointRecheck
+	}
+	s := &endpointSet{policy: policy, recheck: recheck}
+	for i, u := range baseURLs {
+		s.list = append(s.list, &endpoint{index: i, baseURL: u, healthy: true})
+	}
+	return s
+}
+
+// do sends req with httpClient. With several endpoints it directs req to the endpoint
+// chosen by the policy and, after a transport error, fails over to the others while that
+// is safe (see WithEndpoints). apiPath is req's path relative to the base URL.
+func (c *Client) do(httpClient *http.Client, req *http.Request, apiPath string) (*http.Response, error) {
+	if c.endpoints == nil {
+		return httpClient.Do(req)
+	}
+	s := c.endpoints
+	tried := make([]bool, len(s.list))
+	for {
+		ep := s.pick(tried)
+		tried[ep.index] = true
+
+		attempt := req.Clone(req.Context())
+		attempt.URL = joinAPIPath(ep.baseURL, apiPath)
+		attempt.Host = attempt.URL.Host
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+
+		start := time.Now()
+		resp, err := httpClient.Do(attempt)
+		if err == nil {
+			s.markUp(ep, time.Since(start))
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err // Cancelled by the caller: says nothing about the endpoint
+		}
+		s.markDown(ep)
+		if !failoverSafe(req.Method, req.Header.Get("If-Match") != "", err) || s.exhausted(tried) {
+			return nil, err
+		}
+	}
+}
+
+// pick returns the endpoint for the next attempt, skipping those already tried.
+// Healthy endpoints and unhealthy ones due for a re-probe are preferred; if there are
+// none, the untried endpoint that failed longest ago is used anyway.
+func (s *endpointSet) pick(tried []bool) *endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var candidates []*endpoint
+	var fallback *endpoint
+	for _, ep := range s.list {
+		if tried[ep.index] {
+			continue
+		}
+		if ep.healthy || !now.Before(ep.downUntil) {
+			candidates = append(candidates, ep)
+		} else if fallback == nil || ep.downUntil.Before(fallback.downUntil) {
+			fallback = ep
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	switch s.policy {
+	case EndpointRoundRobin:
+		s.next++
+		return candidates[(s.next-1)%len(candidates)]
+	case EndpointLeastLatency:
+		best := candidates[0]
+		for _, ep := range candidates[1:] {
+			if ep.latency < best.latency {
+				best = ep // Unmeasured endpoints (0) are tried first
+			}
+		}
+		return best
+	}
+	return candidates[0]
+}
+
+// exhausted reports whether every endpoint has been tried.
+func (s *endpointSet) exhausted(tried []bool) bool {
+	for _, t := range tried {
+		if !t {
+			return false
+		}
+	}
+	return true
+}
+
+// markUp records a response from ep, which took latency to arrive.
+func (s *endpointSet) markUp(ep *endpoint, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep.healthy = true
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency += time.Duration(endpointLatencyWeight * float64(latency-ep.latency))
+	}
+}
+
+// markDown records a transport error from ep, skipping it until the recheck interval ends.
+func (s *endpointSet) markDown(ep *endpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ep.healthy = false
+	ep.downUntil = time.Now().Add(s.recheck)
+}
+
+// failoverSafe reports whether a request that failed with transport error err may be
+// sent again to another endpoint: idempotent methods always, others only if no
+// connection was established (so the server cannot have received the request).
+// Conditional (If-Match) requests count as non-idempotent: if the first attempt was
+// applied, the version changed and a resend fails with a spurious 412.
+func failoverSafe(method string, conditional bool, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		if !conditional {
+			return true
+		}
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,119 @@
+// endpoints_test.go
+package nebula
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// replica is a test endpoint that answers every request, or drops the connection after
+// reading the request while drop is set.
+type replica struct {
+	*httptest.Server
+	hits atomic.Int32
+	drop atomic.Bool
+}
+
+func newReplica(t *testing.T) *replica {
+	r := &replica{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.hits.Add(1)
+		if r.drop.Load() {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"tables": ["items"], "record_id": 1}`))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestEndpointFailover(t *testing.T) {
+	primary, second := newReplica(t), newReplica(t)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close() // Connections to it are refused
+	client, err := NewClient(down.URL, WithEndpoints(primary.URL, second.URL), WithEndpointRecheck(30*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	ctx := context.Background()
+
+	// A refused connection fails over, and the endpoint is skipped until the recheck
+	if _, err := client.Records.Create(ctx, "shop", "items", map[string]interface{}{"name": "a"}); err != nil {
+		t.Fatalf("POST with the base URL down = %v", err)
+	}
+	if _, err := client.Tables.List(ctx, "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if n := primary.hits.Load(); n != 2 {
+		t.Errorf("first replica got %d requests, want 2", n)
+	}
+	if status := client.Endpoints(); status[0].Healthy || !status[1].Healthy || status[1].Latency == 0 {
+		t.Errorf("Endpoints() = %+v", status)
+	}
+
+	// Requests the server may have received fail over only if resending them is safe
+	primary.drop.Store(true)
+	tests := []struct {
+		name     string
+		call     func() error
+		failover bool
+	}{
+		{"GET", func() error { _, err := client.Tables.List(ctx, "shop"); return err }, true},
+		{"PUT", func() error { return client.Records.Update(ctx, "shop", "items", 1, map[string]interface{}{"n": 1}) }, true},
+		{"PUT with IfMatch", func() error {
+			return client.Records.Update(ctx, "shop", "items", 1, map[string]interface{}{"n": 1}, IfMatch(`"v1"`))
+		}, false},
+		{"POST", func() error {
+			_, err := client.Records.Create(ctx, "shop", "items", map[string]interface{}{"n": 1})
+			return err
+		}, false},
+	}
+	for _, tt := range tests {
+		client.endpoints.mu.Lock()
+		client.endpoints.list[1].healthy = true // Route the request to the dropping replica again
+		client.endpoints.mu.Unlock()
+		primaryHits, secondHits := primary.hits.Load(), second.hits.Load()
+		err := tt.call()
+		if primary.hits.Load() == primaryHits {
+			t.Errorf("%s: not sent to the first replica", tt.name)
+		}
+		if failedOver := second.hits.Load() > secondHits; failedOver != tt.failover || (err == nil) != tt.failover {
+			t.Errorf("%s: failed over = %v, err = %v; want failover %v", tt.name, failedOver, err, tt.failover)
+		}
+	}
+
+	// After the recheck interval live traffic probes the endpoints again
+	primary.drop.Store(false)
+	time.Sleep(40 * time.Millisecond)
+	primaryHits := primary.hits.Load()
+	if _, err := client.Tables.List(ctx, "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if primary.hits.Load() != primaryHits+1 || !client.Endpoints()[1].Healthy {
+		t.Errorf("recovered replica not used again: %+v", client.Endpoints())
+	}
+}
+
+func TestEndpointRoundRobin(t *testing.T) {
+	a, b := newReplica(t), newReplica(t)
+	client, err := NewClient(a.URL, WithEndpoints(b.URL), WithEndpointPolicy(EndpointRoundRobin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	for range 4 {
+		if _, err := client.Tables.List(context.Background(), "shop"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.hits.Load() != 2 || b.hits.Load() != 2 {
+		t.Errorf("requests per endpoint = %d, %d; want 2, 2", a.hits.Load(), b.hits.Load())
+	}
+}
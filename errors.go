@@ -5,40 +5,99 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Standard errors returned by the SDK
 var (
-	ErrBadRequest         = errors.New("bad request (400)")
-	ErrUnauthorized       = errors.New("unauthorized (401 - check credentials/token)")
-	ErrForbidden          = errors.New("forbidden (403)")
-	ErrNotFound           = errors.New("resource not found (404)")
-	ErrConflict           = errors.New("conflict (409 - e.g., resource already exists)")
-	ErrRateLimited        = errors.New("rate limit exceeded (429)")
-	ErrInternalServer     = errors.New("internal server error (500)")
-	ErrInvalidResponse    = errors.New("invalid response from server")
-	ErrAuthTokenMissing   = errors.New("authentication token not set in client")
-	ErrDatabaseExists     = errors.New("database name already exists for this user")         // Specific example
-	ErrDatabaseNotFound   = errors.New("database not found or not registered for this user") // Specific example
-	ErrRecordNotFound     = errors.New("record not found")                                   // Specific example
-	ErrTableNotFound      = errors.New("table not found")                                    // Specific example
-	ErrInvalidFilterValue = errors.New("invalid value provided for filter")                  // Specific example
+	ErrBadRequest          = errors.New("bad request (400)")
+	ErrUnauthorized        = errors.New("unauthorized (401 - check credentials/token)")
+	ErrForbidden           = errors.New("forbidden (403)")
+	ErrNotFound            = errors.New("resource not found (404)")
+	ErrConflict            = errors.New("conflict (409 - e.g., resource already exists)")
+	ErrPreconditionFailed  = errors.New("precondition failed (412 - record version changed)")
+	ErrRateLimited         = errors.New("rate limit exceeded (429)")
+	ErrInternalServer      = errors.New("internal server error (500)")
+	ErrBadGateway          = errors.New("bad gateway (502)")
+	ErrServiceUnavailable  = errors.New("service unavailable (503)")
+	ErrGatewayTimeout      = errors.New("gateway timeout (504)")
+	ErrInvalidResponse     = errors.New("invalid response from server")
+	ErrResponseTooLarge    = errors.New("response body exceeds configured size limit")
+	ErrAuthTokenMissing    = errors.New("authentication token not set in client")
+	ErrValidation          = errors.New("request failed client-side validation") // See ValidationError
+	ErrColumnNotFound      = errors.New("column not present in record")          // See ColumnTypeError
+	ErrColumnType          = errors.New("column value has unexpected type")      // See ColumnTypeError
+	ErrVersionUnavailable  = errors.New("server did not return a record version (ETag)")
+	ErrTransactionDone     = errors.New("transaction has already been committed or rolled back")
+	ErrBackupCorrupt       = errors.New("backup archive is corrupt or incomplete")
+	ErrRestoreMismatch     = errors.New("restored row count does not match the backup")
+	ErrUnsupportedByServer = errors.New("feature not supported by this Nebula server") // See ServerInfo
+	ErrDatabaseExists      = wrapSentinel("database name already exists for this user", ErrConflict)
+	ErrDatabaseNotFound    = wrapSentinel("database not found or not registered for this user", ErrNotFound)
+	ErrRecordNotFound      = wrapSentinel("record not found", ErrNotFound)
+	ErrTableNotFound       = wrapSentinel("table not found", ErrNotFound)
+	ErrInvalidFilterValue  = wrapSentinel("invalid value provided for filter", ErrBadRequest)
 	// Add other specific, exported errors as needed
 )
 
+// sentinelError is a resource-specific error that also matches the status error it
+// refines (e.g., errors.Is(ErrRecordNotFound, ErrNotFound) is true).
+type sentinelError struct {
+	msg  string
+	base error
+}
+
+func (e *sentinelError) Error() string { return e.msg }
+func (e *sentinelError) Unwrap() error { return e.base }
+
+// wrapSentinel returns a sentinel error with message msg that wraps base.
+func wrapSentinel(msg string, base error) error {
+	return &sentinelError{msg: msg, base: base}
+}
+
+// Error codes the Nebula API may send in the "code" field of an error response.
+// They take precedence over the endpoint-based mapping in resourceError.
+var serverErrorCodes = map[string]error{
+	"DATABASE_EXISTS":      ErrDatabaseExists,
+	"DATABASE_NOT_FOUND":   ErrDatabaseNotFound,
+	"TABLE_NOT_FOUND":      ErrTableNotFound,
+	"RECORD_NOT_FOUND":     ErrRecordNotFound,
+	"INVALID_FILTER_VALUE": ErrInvalidFilterValue,
+}
+
 // APIError provides more context for errors returned by the Nebula API.
 type APIError struct {
-	StatusCode int    // The HTTP status code returned by the API.
-	Message    string // The error message from the API response body (`{"error": "..."}`).
-	Err        error  // Optional: underlying error (e.g., network error, json parsing error).
+	StatusCode int           // The HTTP status code returned by the API.
+	Code       string        // Optional machine-readable error code from the API response body (`{"code": "..."}`).
+	Message    string        // The error message from the API response body (`{"error": "..."}`).
+	Operation  string        // The SDK operation that failed (e.g., "Records.Get").
+	Method     string        // The HTTP method of the failed request.
+	Path       string        // The API path of the failed request (without base URL).
+	RequestID  string        // The X-Request-ID returned by the server, if any.
+	RetryAfter time.Duration // Delay requested via the Retry-After header (429/503), or 0.
+	Details    []FieldError  // Per-field validation details from structured error bodies, if any.
+	Err        error         // Optional: underlying error (e.g., network error, json parsing error).
 }
 
 // Error implements the error interface for APIError.
 func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API error (status %d)", e.StatusCode)
+	if e.Operation != "" {
+		fmt.Fprintf(&b, " in %s", e.Operation)
+	}
+	if e.Method != "" || e.Path != "" {
+		fmt.Fprintf(&b, " [%s %s]", e.Method, e.Path)
+	}
+	fmt.Fprintf(&b, ": %s", e.Message)
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request_id=%s)", e.RequestID)
+	}
 	if e.Err != nil {
-		return fmt.Sprintf("API error (status %d): %s [caused by: %v]", e.StatusCode, e.Message, e.Err)
+		fmt.Fprintf(&b, " [caused by: %v]", e.Err)
 	}
-	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	return b.String()
 }
 
 // Unwrap allows retrieving the underlying error using errors.Is/As.
@@ -46,51 +105,110 @@ func (e *APIError) Unwrap() error {
 	return e.Err
 }
 
-// MapHTTPError maps an HTTP status code and an optional underlying error
-// to one of the exported SDK error variables or a generic APIError.
-// This function is intended for internal SDK use (in request.go).
-func mapHTTPError(statusCode int, apiMsg string, underlyingErr error) error {
-	// Use default message if API message is empty
-	if apiMsg == "" {
-		apiMsg = "API returned status " + fmt.Sprintf("%d", statusCode)
-	}
-
-	// Map status codes to specific error variables
-	var baseErr error
+// statusError maps an HTTP status code to one of the exported SDK error variables.
+func statusError(statusCode int) error {
 	switch statusCode {
 	case http.StatusBadRequest: // 400
-		baseErr = ErrBadRequest
+		return ErrBadRequest
 	case http.StatusUnauthorized: // 401
-		baseErr = ErrUnauthorized
+		return ErrUnauthorized
 	case http.StatusForbidden: // 403
-		baseErr = ErrForbidden
+		return ErrForbidden
 	case http.StatusNotFound: // 404
-		baseErr = ErrNotFound
+		return ErrNotFound
 	case http.StatusConflict: // 409
-		baseErr = ErrConflict
+		return ErrConflict
+	case http.StatusPreconditionFailed: // 412
+		return ErrPreconditionFailed
 	case http.StatusTooManyRequests: // 429
-		baseErr = ErrRateLimited
+		return ErrRateLimited
 	case http.StatusInternalServerError: // 500
-		baseErr = ErrInternalServer
-	// Add other common codes (502, 503, 504) if needed
-	default:
-		// For unmapped client/server errors, use a generic error
-		if statusCode >= 400 && statusCode < 500 {
-			baseErr = fmt.Errorf("unexpected client error")
-		} else if statusCode >= 500 {
-			baseErr = fmt.Errorf("unexpected server error")
-		} else {
-			// Should not happen if checking > 400, but just in case
-			baseErr = fmt.Errorf("unexpected status code")
+		return ErrInternalServer
+	case http.StatusBadGateway: // 502
+		return ErrBadGateway
+	case http.StatusServiceUnavailable: // 503
+		return ErrServiceUnavailable
+	case http.StatusGatewayTimeout: // 504
+		return ErrGatewayTimeout
+	}
+	// For unmapped client/server errors, use a generic error
+	if statusCode >= 400 && statusCode < 500 {
+		return errors.New("unexpected client error")
+	} else if statusCode >= 500 {
+		return errors.New("unexpected server error")
+	}
+	// Should not happen if checking > 400, but just in case
+	return errors.New("unexpected status code")
+}
+
+// resourceError returns the resource-specific SDK error implied by a status code
+// on a given endpoint, or nil if the status has no resource-specific meaning there.
+// A server-provided error code always wins over the endpoint context.
+func resourceError(statusCode int, kind resourceKind, method, apiCode string) error {
+	if apiCode != "" {
+		if err, ok := serverErrorCodes[strings.ToUpper(apiCode)]; ok {
+			return err
 		}
 	}
 
-	// Return wrapped APIError
+	switch statusCode {
+	case http.StatusNotFound:
+		switch kind {
+		case resourceDatabase:
+			return ErrDatabaseNotFound
+		case resourceTable:
+			return ErrTableNotFound
+		case resourceRecord:
+			return ErrRecordNotFound
+		}
+	case http.StatusConflict:
+		if kind == resourceDatabases && method == http.MethodPost {
+			return ErrDatabaseExists
+		}
+	}
+	return nil
+}
+
+// mapHTTPError maps an HTTP status code, the addressed endpoint and an optional
+// underlying error to an *APIError whose chain contains the matching exported SDK
+// error variables, so both errors.Is(err, ErrNotFound) and errors.Is(err, ErrTableNotFound) hold.
+// This function is intended for internal SDK use (in request.go).
+func mapHTTPError(statusCode int, kind resourceKind, method, apiCode, apiMsg string, underlyingErr error) *APIError {
+	// Use default message if API message is empty
+	if apiMsg == "" {
+		apiMsg = fmt.Sprintf("API returned status %d", statusCode)
+	}
+
+	// Most specific error first, then the status error, then the cause (if any)
+	chain := make([]error, 0, 3)
+	if specific := resourceError(statusCode, kind, method, apiCode); specific != nil {
+		chain = append(chain, specific)
+	}
+	chain = append(chain, statusError(statusCode))
+	if underlyingErr != nil {
+		chain = append(chain, underlyingErr)
+	}
+
 	return &APIError{
 		StatusCode: statusCode,
+		Code:       apiCode,
 		Message:    apiMsg,
-		Err:        fmt.Errorf("%w: %w", baseErr, underlyingErr), // Wrap base and underlying
+		Err:        wrapErrors(chain...),
+	}
+}
+
+// wrapErrors wraps errs into a single error whose message joins them with ": "
+// and whose chain matches every element via errors.Is/As.
+func wrapErrors(errs ...error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	format := strings.TrimSuffix(strings.Repeat("%w: ", len(errs)), ": ")
+	args := make([]interface{}, len(errs))
+	for i, err := range errs {
+		args[i] = err
 	}
+	return fmt.Errorf(format, args...)
 }
 
 // Helper to check if an error is specifically *our* APIError with a given status code
@@ -0,0 +1,113 @@
+// errors_test.go
+package nebula
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// resourceSentinels are the endpoint-specific errors; at most one may match a response.
+var resourceSentinels = []error{ErrDatabaseExists, ErrDatabaseNotFound, ErrTableNotFound, ErrRecordNotFound, ErrInvalidFilterValue}
+
+func TestMapHTTPError(t *testing.T) {
+	const (
+		dbs     = "/api/v1/databases"
+		db      = "/api/v1/databases/shop"
+		tables  = "/api/v1/databases/shop/tables"
+		table   = "/api/v1/databases/shop/tables/orders"
+		records = "/api/v1/databases/shop/tables/orders/records"
+		record  = "/api/v1/databases/shop/tables/orders/records/42"
+	)
+	tests := []struct {
+		method string
+		path   string
+		status int
+		code   string // Server error code, if any
+		want   error  // Resource sentinel expected, or nil for none
+		base   error  // Status sentinel expected
+	}{
+		// 404 on every known resource shape
+		{http.MethodGet, dbs, 404, "", nil, ErrNotFound},
+		{http.MethodDelete, db, 404, "", ErrDatabaseNotFound, ErrNotFound},
+		{http.MethodPost, db + "/schema", 404, "", ErrDatabaseNotFound, ErrNotFound},
+		{http.MethodGet, tables, 404, "", ErrDatabaseNotFound, ErrNotFound},
+		{http.MethodDelete, table, 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodGet, table + "/schema", 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodGet, records, 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodGet, records + "?limit=10&sort=id:asc", 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodPost, records, 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodPatch, records + "?status=open", 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodDelete, records + "?all_rows=true", 404, "", ErrTableNotFound, ErrNotFound},
+		{http.MethodGet, record, 404, "", ErrRecordNotFound, ErrNotFound},
+		{http.MethodPut, record, 404, "", ErrRecordNotFound, ErrNotFound},
+		{http.MethodDelete, record, 404, "", ErrRecordNotFound, ErrNotFound},
+
+		// 404 on sub-endpoints an older server may not route: generic only
+		{http.MethodPost, db + "/transaction", 404, "", nil, ErrNotFound},
+		{http.MethodGet, records + "/count", 404, "", nil, ErrNotFound},
+		{http.MethodPost, records + "/aggregate", 404, "", nil, ErrNotFound},
+		{http.MethodGet, records + "/batch?ids=1,2", 404, "", nil, ErrNotFound},
+		{http.MethodGet, records + "/watch", 404, "", nil, ErrNotFound},
+		{http.MethodGet, record + "/history", 404, "", nil, ErrNotFound},
+		{http.MethodGet, db + "/unknown", 404, "", nil, ErrNotFound},
+		{http.MethodGet, "/api/v1/unknown", 404, "", nil, ErrNotFound},
+		{http.MethodPost, "auth/login", 404, "", nil, ErrNotFound},
+
+		// Server error codes win over the endpoint context
+		{http.MethodGet, records + "/count", 404, "TABLE_NOT_FOUND", ErrTableNotFound, ErrNotFound},
+		{http.MethodPost, db + "/transaction", 404, "database_not_found", ErrDatabaseNotFound, ErrNotFound},
+		{http.MethodGet, record, 404, "DATABASE_NOT_FOUND", ErrDatabaseNotFound, ErrNotFound},
+		{http.MethodGet, records, 400, "INVALID_FILTER_VALUE", ErrInvalidFilterValue, ErrBadRequest},
+		{http.MethodGet, record, 404, "SOMETHING_NEW", ErrRecordNotFound, ErrNotFound},
+
+		// 409 is only "database exists" when creating a database
+		{http.MethodPost, dbs, 409, "", ErrDatabaseExists, ErrConflict},
+		{http.MethodGet, dbs, 409, "", nil, ErrConflict},
+		{http.MethodPost, records, 409, "", nil, ErrConflict},
+		{http.MethodPost, db + "/transaction", 409, "", nil, ErrConflict},
+
+		// Other statuses carry no resource meaning anywhere
+		{http.MethodGet, record, 400, "", nil, ErrBadRequest},
+		{http.MethodPost, "auth/login", 401, "", nil, ErrUnauthorized},
+		{http.MethodDelete, db, 403, "", nil, ErrForbidden},
+		{http.MethodPut, record, 412, "", nil, ErrPreconditionFailed},
+		{http.MethodGet, records, 429, "", nil, ErrRateLimited},
+		{http.MethodGet, table + "/schema", 500, "", nil, ErrInternalServer},
+		{http.MethodGet, record, 502, "", nil, ErrBadGateway},
+		{http.MethodPost, records, 503, "", nil, ErrServiceUnavailable},
+		{http.MethodGet, tables, 504, "", nil, ErrGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		name := tt.method + " " + tt.path + " " + http.StatusText(tt.status)
+		t.Run(name, func(t *testing.T) {
+			err := error(mapHTTPError(tt.status, resourceFromPath(tt.path), tt.method, tt.code, "", nil))
+			if !errors.Is(err, tt.base) {
+				t.Errorf("error %v does not match %v", err, tt.base)
+			}
+			for _, sentinel := range resourceSentinels {
+				if got, want := errors.Is(err, sentinel), sentinel == tt.want; got != want {
+					t.Errorf("errors.Is(%v, %v) = %v, want %v", err, sentinel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResourceSentinelsWrapStatus(t *testing.T) {
+	tests := []struct {
+		sentinel, base error
+	}{
+		{ErrDatabaseExists, ErrConflict},
+		{ErrDatabaseNotFound, ErrNotFound},
+		{ErrTableNotFound, ErrNotFound},
+		{ErrRecordNotFound, ErrNotFound},
+		{ErrInvalidFilterValue, ErrBadRequest},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.sentinel, tt.base) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", tt.sentinel, tt.base)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// arrow.go
+package exporter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// Arrow IPC constants (see format/Message.fbs and format/Schema.fbs in the Arrow repository).
+const (
+	arrowMetadataV5 = 4 // MetadataVersion.V5
+
+	arrowHeaderSchema      = 1 // MessageHeader.Schema
+	arrowHeaderRecordBatch = 3 // MessageHeader.RecordBatch
+
+	arrowTypeInt           = 2 // Type.Int
+	arrowTypeFloatingPoint = 3 // Type.FloatingPoint
+	arrowTypeBinary        = 4 // Type.Binary
+	arrowTypeUtf8          = 5 // Type.Utf8
+	arrowTypeBool          = 6 // Type.Bool
+
+	arrowPrecisionDouble = 2 // Precision.DOUBLE
+
+	arrowContinuation = 0xFFFFFFFF // Marks the start of each encapsulated message
+)
+
+// arrowWriter writes records as an Arrow IPC stream: a Schema message, one RecordBatch
+// message per page and an end-of-stream marker. Column types map as INTEGER → int64,
+// REAL → float64, BOOLEAN → bool, BLOB → binary, and TEXT (or any other type) → utf8.
+// All fields are nullable.
+type arrowWriter struct {
+	w    io.Writer
+	cols []column
+}
+
+func newArrowWriter(w io.Writer, cols []column) *arrowWriter {
+	return &arrowWriter{w: w, cols: cols}
+}
+
+func (a *arrowWriter) begin() error {
+	fields := make(fbTables, len(a.cols))
+	for i, col := range a.cols {
+		typeID, typ := arrowType(col.typ)
+		fields[i] = fbTable{
+			fbString(col.name), // name
+			fbBool(true),       // nullable
+			fbUint8(typeID),    // type_type
+			typ,                // type
+			nil,                // dictionary
+			fbTables{},         // children (required by readers even when empty)
+		}
+	}
+	schema := fbTable{
+		fbInt16(0), // endianness: Little
+		fields,     // fields
+	}
+	return a.writeMessage(arrowHeaderSchema, schema, nil)
+}
+
+func (a *arrowWriter) writePage(records []nebula.Record) error {
+	var (
+		body    []byte
+		nodes   []byte
+		buffers []byte
+	)
+	addBuffer := func(b []byte) {
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(b)))
+		body = append(body, b...)
+		for len(body)%8 != 0 { // Each buffer starts 8-byte aligned
+			body = append(body, 0)
+		}
+	}
+
+	for _, col := range a.cols {
+		c, err := buildArrowColumn(col, records)
+		if err != nil {
+			return err
+		}
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(len(records)))
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(c.nulls))
+		addBuffer(c.validity)
+		if c.offsets != nil {
+			addBuffer(c.offsets)
+		}
+		addBuffer(c.data)
+	}
+
+	batch := fbTable{
+		fbInt64(int64(len(records))),       // length
+		fbStructs{size: 16, data: nodes},   // nodes: FieldNode{length, null_count}
+		fbStructs{size: 16, data: buffers}, // buffers: Buffer{offset, length}
+	}
+	if err := a.writeMessage(arrowHeaderRecordBatch, batch, body); err != nil {
+		return fmt.Errorf("exporter: writing output: %w", err)
+	}
+	return nil
+}
+
+func (a *arrowWriter) end() error {
+	_, err := a.w.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}) // End-of-stream marker
+	return err
+}
+
+// writeMessage writes an encapsulated IPC message: the continuation marker, the metadata
+// length, the Message flatbuffer (padded to 8 bytes) and the body.
+func (a *arrowWriter) writeMessage(headerType uint8, header fbTable, body []byte) error {
+	meta := encodeFlatbuffer(fbTable{
+		fbInt16(arrowMetadataV5),  // version
+		fbUint8(headerType),       // header_type
+		header,                    // header
+		fbInt64(int64(len(body))), // bodyLength
+	})
+	prefix := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+	prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(meta)))
+	for _, b := range [][]byte{prefix, meta, body} {
+		if _, err := a.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// arrowType returns the Arrow Type union tag and table for a column type.
+func arrowType(typ string) (uint8, fbTable) {
+	switch typ {
+	case "INTEGER":
+		return arrowTypeInt, fbTable{fbInt32(64), fbBool(true)} // bitWidth, is_signed
+	case "REAL":
+		return arrowTypeFloatingPoint, fbTable{fbInt16(arrowPrecisionDouble)}
+	case "BOOLEAN":
+		return arrowTypeBool, fbTable{}
+	case "BLOB":
+		return arrowTypeBinary, fbTable{}
+	default:
+		return arrowTypeUtf8, fbTable{}
+	}
+}
+
+// arrowColumn holds the buffers of one column of a record batch.
+type arrowColumn struct {
+	validity []byte // Bit i set if row i is not NULL
+	offsets  []byte // int32 value offsets, for utf8 and binary columns only
+	data     []byte // Fixed-width values, bit-packed booleans, or concatenated bytes
+	nulls    int
+}
+
+// buildArrowColumn converts the values of col in records to Arrow buffers.
+func buildArrowColumn(col column, records []nebula.Record) (*arrowColumn, error) {
+	n := len(records)
+	c := &arrowColumn{validity: make([]byte, (n+7)/8)}
+	typeID, _ := arrowType(col.typ)
+	switch typeID {
+	case arrowTypeBool:
+		c.data = make([]byte, (n+7)/8)
+	case arrowTypeUtf8, arrowTypeBinary:
+		c.offsets = make([]byte, 4, 4*(n+1)) // Leading zero offset
+	}
+
+	for i, rec := range records {
+		v, err := value(rec, col)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			c.nulls++
+		} else {
+			c.validity[i/8] |= 1 << (i % 8)
+		}
+
+		switch typeID {
+		case arrowTypeInt:
+			x, _ := v.(int64) // NULL slots hold zero
+			c.data = binary.LittleEndian.AppendUint64(c.data, uint64(x))
+		case arrowTypeFloatingPoint:
+			x, _ := v.(float64)
+			c.data = binary.LittleEndian.AppendUint64(c.data, math.Float64bits(x))
+		case arrowTypeBool:
+			if x, _ := v.(bool); x {
+				c.data[i/8] |= 1 << (i % 8)
+			}
+		case arrowTypeBinary:
+			x, _ := v.([]byte)
+			c.data = append(c.data, x...)
+		default: // utf8
+			if v != nil {
+				c.data = append(c.data, formatText(v)...)
+			}
+		}
+		if c.offsets != nil {
+			if len(c.data) > math.MaxInt32 {
+				return nil, fmt.Errorf("exporter: column %q exceeds 2GB in one page; lower Options.PageSize", col.name)
+			}
+			c.offsets = binary.LittleEndian.AppendUint32(c.offsets, uint32(len(c.data)))
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,381 @@
+// arrow_test.go
+package exporter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// TestArrowRoundTrip writes pages with every column type and NULLs, then reads the
+// stream back with an independent reader following the Arrow IPC and FlatBuffers specs.
+func TestArrowRoundTrip(t *testing.T) {
+	cols := []column{
+		{name: "id", typ: "INTEGER"},
+		{name: "price", typ: "REAL"},
+		{name: "name", typ: "TEXT"},
+		{name: "active", typ: "BOOLEAN"},
+		{name: "payload", typ: "BLOB"},
+		{name: "created", typ: "DATETIME"}, // Unknown types are exported as utf8
+	}
+	blob := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	pages := [][]nebula.Record{
+		{
+			{"id": int64(1), "price": 9.5, "name": "widget", "active": true, "payload": blob("\x00\x01"), "created": "2026-01-02"},
+			{"id": int64(2), "price": nil, "name": nil, "active": nil, "payload": nil, "created": nil},
+			{"id": int64(-3), "price": int64(3), "name": "", "active": false, "payload": blob(""), "created": "x"},
+		},
+		{}, // An empty page is still a valid record batch
+	}
+	var long []nebula.Record // Crosses a validity byte boundary
+	for i := int64(0); i < 10; i++ {
+		rec := nebula.Record{"id": 100 + i, "name": "naïve ✓"}
+		if i%3 == 0 {
+			rec["active"], rec["price"] = true, -0.25
+		}
+		long = append(long, rec)
+	}
+	pages = append(pages, long)
+
+	var buf bytes.Buffer
+	a := newArrowWriter(&buf, cols)
+	if err := a.begin(); err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range pages {
+		if err := a.writePage(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.end(); err != nil {
+		t.Fatal(err)
+	}
+
+	fields, batches := readArrowStream(t, buf.Bytes())
+
+	wantFields := []arrowField{
+		{name: "id", typ: "int64"},
+		{name: "price", typ: "float64"},
+		{name: "name", typ: "utf8"},
+		{name: "active", typ: "bool"},
+		{name: "payload", typ: "binary"},
+		{name: "created", typ: "utf8"},
+	}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Fatalf("schema = %+v, want %+v", fields, wantFields)
+	}
+	if len(batches) != len(pages) {
+		t.Fatalf("got %d record batches, want %d", len(batches), len(pages))
+	}
+	for p, page := range pages {
+		for c, col := range cols {
+			want := make([]interface{}, len(page))
+			for r, rec := range page {
+				v, err := value(rec, col)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want[r] = v
+			}
+			if got := batches[p][c]; !reflect.DeepEqual(got, want) {
+				t.Errorf("page %d column %s = %#v, want %#v", p, col.name, got, want)
+			}
+		}
+	}
+
+	// Spot-check conversions independently of value
+	if got := batches[0][1][2]; got != float64(3) {
+		t.Errorf("REAL 3 read back as %#v", got)
+	}
+	if got := batches[0][4][0]; !bytes.Equal(got.([]byte), []byte{0, 1}) {
+		t.Errorf("BLOB read back as %#v", got)
+	}
+	if got := batches[0][4][2]; got == nil || len(got.([]byte)) != 0 {
+		t.Errorf("empty BLOB read back as %#v, want non-NULL empty", got)
+	}
+}
+
+// arrowField is a schema field as read back: its name and a short type name.
+type arrowField struct {
+	name string
+	typ  string
+}
+
+// readArrowStream decodes an Arrow IPC stream of one Schema message followed by record
+// batches and the end-of-stream marker, returning the fields and each batch's column
+// values (nil for NULL).
+func readArrowStream(t *testing.T, stream []byte) ([]arrowField, [][][]interface{}) {
+	t.Helper()
+	var fields []arrowField
+	var batches [][][]interface{}
+	for pos := 0; ; {
+		if len(stream)-pos < 8 {
+			t.Fatalf("stream truncated at %d", pos)
+		}
+		if cont := binary.LittleEndian.Uint32(stream[pos:]); cont != 0xFFFFFFFF {
+			t.Fatalf("message at %d: continuation marker %#x", pos, cont)
+		}
+		metaLen := int(binary.LittleEndian.Uint32(stream[pos+4:]))
+		pos += 8
+		if metaLen == 0 {
+			if pos != len(stream) {
+				t.Fatalf("%d bytes after the end-of-stream marker", len(stream)-pos)
+			}
+			break
+		}
+		if pos%8 != 0 || metaLen%8 != 0 {
+			t.Fatalf("message at %d: metadata of %d bytes is not 8-byte aligned", pos, metaLen)
+		}
+		msg := fbRoot(t, stream[pos:pos+metaLen])
+		pos += metaLen
+		if v := msg.int16(0); v != 4 {
+			t.Fatalf("metadata version %d, want V5", v)
+		}
+		bodyLen := int(msg.int64(3))
+		body := stream[pos : pos+bodyLen]
+		pos += bodyLen
+
+		switch headerType := msg.uint8(1); headerType {
+		case 1: // Schema
+			if fields != nil || batches != nil {
+				t.Fatal("unexpected second schema message")
+			}
+			schema := msg.table(2)
+			if e := schema.int16(0); e != 0 {
+				t.Fatalf("endianness %d, want little", e)
+			}
+			for _, f := range schema.tables(1) {
+				if !f.bool(1) {
+					t.Errorf("field %q is not nullable", f.string(0))
+				}
+				if !f.has(5) {
+					t.Errorf("field %q has no children vector", f.string(0))
+				}
+				fields = append(fields, arrowField{name: f.string(0), typ: readArrowType(t, f.uint8(2), f.table(3))})
+			}
+		case 3: // RecordBatch
+			if fields == nil {
+				t.Fatal("record batch before the schema")
+			}
+			batches = append(batches, readRecordBatch(t, msg.table(2), body, fields))
+		default:
+			t.Fatalf("unexpected message header type %d", headerType)
+		}
+	}
+	return fields, batches
+}
+
+// readArrowType returns the short name of an Arrow Type union value.
+func readArrowType(t *testing.T, typeType uint8, typ fbReader) string {
+	t.Helper()
+	switch typeType {
+	case 2:
+		if typ.int32(0) == 64 && typ.bool(1) {
+			return "int64"
+		}
+	case 3:
+		if typ.int16(0) == 2 {
+			return "float64"
+		}
+	case 4:
+		return "binary"
+	case 5:
+		return "utf8"
+	case 6:
+		return "bool"
+	}
+	t.Fatalf("unexpected Arrow type %d", typeType)
+	return ""
+}
+
+// readRecordBatch decodes the columns of a RecordBatch message with the given body.
+func readRecordBatch(t *testing.T, batch fbReader, body []byte, fields []arrowField) [][]interface{} {
+	t.Helper()
+	length := int(batch.int64(0))
+	nodes := batch.structs(1, 16)
+	buffers := batch.structs(2, 16)
+	if len(nodes) != len(fields) {
+		t.Fatalf("%d field nodes for %d fields", len(nodes), len(fields))
+	}
+	nextBuffer := func() []byte {
+		if len(buffers) == 0 {
+			t.Fatal("too few buffers")
+		}
+		off := binary.LittleEndian.Uint64(buffers[0])
+		n := binary.LittleEndian.Uint64(buffers[0][8:])
+		buffers = buffers[1:]
+		if off%8 != 0 || off+n > uint64(len(body)) {
+			t.Fatalf("buffer [%d, +%d) misaligned or outside the %d-byte body", off, n, len(body))
+		}
+		return body[off : off+n]
+	}
+	bit := func(b []byte, i int) bool { return b[i/8]&(1<<(i%8)) != 0 }
+
+	columns := make([][]interface{}, len(fields))
+	for c, f := range fields {
+		if n := int(binary.LittleEndian.Uint64(nodes[c])); n != length {
+			t.Fatalf("field %s: node length %d, batch length %d", f.name, n, length)
+		}
+		nulls := int(binary.LittleEndian.Uint64(nodes[c][8:]))
+		validity := nextBuffer()
+		var offsets []byte
+		if f.typ == "utf8" || f.typ == "binary" {
+			offsets = nextBuffer()
+		}
+		data := nextBuffer()
+
+		values := make([]interface{}, length)
+		for i := range values {
+			if !bit(validity, i) {
+				nulls--
+				continue
+			}
+			switch f.typ {
+			case "int64":
+				values[i] = int64(binary.LittleEndian.Uint64(data[8*i:]))
+			case "float64":
+				values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+			case "bool":
+				values[i] = bit(data, i)
+			case "utf8", "binary":
+				start := binary.LittleEndian.Uint32(offsets[4*i:])
+				end := binary.LittleEndian.Uint32(offsets[4*i+4:])
+				if f.typ == "utf8" {
+					values[i] = string(data[start:end])
+				} else {
+					values[i] = append([]byte{}, data[start:end]...)
+				}
+			}
+		}
+		if nulls != 0 {
+			t.Errorf("field %s: null count off by %d", f.name, nulls)
+		}
+		columns[c] = values
+	}
+	if len(buffers) != 0 {
+		t.Fatalf("%d unused buffers", len(buffers))
+	}
+	return columns
+}
+
+// fbReader reads a FlatBuffers table at pos of buf.
+type fbReader struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+// fbRoot returns the root table of a FlatBuffers buffer.
+func fbRoot(t *testing.T, buf []byte) fbReader {
+	return fbReader{t: t, buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of field id's inline value, or 0 if it is absent.
+func (r fbReader) field(id int) int {
+	vtable := r.pos - int(int32(binary.LittleEndian.Uint32(r.buf[r.pos:])))
+	vtableLen := int(binary.LittleEndian.Uint16(r.buf[vtable:]))
+	if 4+2*id >= vtableLen {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(r.buf[vtable+4+2*id:]))
+	if off == 0 {
+		return 0
+	}
+	return r.pos + off
+}
+
+func (r fbReader) has(id int) bool { return r.field(id) != 0 }
+
+// scalar returns the n bytes of field id, checking their alignment, or nil if absent.
+func (r fbReader) scalar(id, n int) []byte {
+	p := r.field(id)
+	if p == 0 {
+		return nil
+	}
+	if p%n != 0 {
+		r.t.Fatalf("field %d at %d is not %d-byte aligned", id, p, n)
+	}
+	return r.buf[p : p+n]
+}
+
+func (r fbReader) bool(id int) bool { return r.uint8(id) != 0 }
+
+func (r fbReader) uint8(id int) uint8 {
+	if b := r.scalar(id, 1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r fbReader) int16(id int) int16 {
+	if b := r.scalar(id, 2); b != nil {
+		return int16(binary.LittleEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r fbReader) int32(id int) int32 {
+	if b := r.scalar(id, 4); b != nil {
+		return int32(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r fbReader) int64(id int) int64 {
+	if b := r.scalar(id, 8); b != nil {
+		return int64(binary.LittleEndian.Uint64(b))
+	}
+	return 0
+}
+
+// deref follows the uoffset stored in field id, failing if the field is absent.
+func (r fbReader) deref(id int) int {
+	p := r.field(id)
+	if p == 0 {
+		r.t.Fatalf("required field %d is absent", id)
+	}
+	return p + int(binary.LittleEndian.Uint32(r.buf[p:]))
+}
+
+func (r fbReader) table(id int) fbReader {
+	return fbReader{t: r.t, buf: r.buf, pos: r.deref(id)}
+}
+
+func (r fbReader) string(id int) string {
+	p := r.deref(id)
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	if r.buf[p+4+n] != 0 {
+		r.t.Fatalf("string field %d is not NUL-terminated", id)
+	}
+	return string(r.buf[p+4 : p+4+n])
+}
+
+func (r fbReader) tables(id int) []fbReader {
+	p := r.deref(id)
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	tables := make([]fbReader, n)
+	for i := range tables {
+		slot := p + 4 + 4*i
+		tables[i] = fbReader{t: r.t, buf: r.buf, pos: slot + int(binary.LittleEndian.Uint32(r.buf[slot:]))}
+	}
+	return tables
+}
+
+// structs returns the elements of a vector of size-byte structs, which must be 8-byte aligned.
+func (r fbReader) structs(id, size int) [][]byte {
+	p := r.deref(id)
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	if (p+4)%8 != 0 {
+		r.t.Fatalf("struct vector %d elements at %d are not 8-byte aligned", id, p+4)
+	}
+	elems := make([][]byte, n)
+	for i := range elems {
+		elems[i] = r.buf[p+4+size*i : p+4+size*(i+1)]
+	}
+	return elems
+}
@@ -0,0 +1,84 @@
+// csv.go
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// csvWriter writes records as CSV with a header row in column order.
+type csvWriter struct {
+	w         *csv.Writer
+	cols      []column
+	nullValue string
+	row       []string // Reused for every record
+}
+
+func newCSVWriter(w io.Writer, cols []column, opts Options) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+	return &csvWriter{w: cw, cols: cols, nullValue: opts.NullValue, row: make([]string, len(cols))}
+}
+
+func (c *csvWriter) begin() error {
+	for i, col := range c.cols {
+		c.row[i] = col.name
+	}
+	return c.w.Write(c.row)
+}
+
+func (c *csvWriter) writePage(records []nebula.Record) error {
+	for _, rec := range records {
+		for i, col := range c.cols {
+			v, err := value(rec, col)
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				c.row[i] = c.nullValue
+			} else {
+				c.row[i] = formatText(v)
+			}
+		}
+		if err := c.w.Write(c.row); err != nil {
+			return fmt.Errorf("exporter: writing output: %w", err)
+		}
+	}
+	c.w.Flush() // Hand each page to the underlying writer rather than buffering the table
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("exporter: writing output: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatText renders a non-NULL value as CSV text.
+func formatText(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case []byte:
+		return encodeBlob(t)
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,175 @@
+// flatbuf.go
+package exporter
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// A minimal FlatBuffers encoder, just enough for the Arrow IPC metadata messages.
+// Objects are laid out front to back: each table is preceded by its vtable and followed
+// by the strings, vectors and tables it references, so every uoffset points forward as
+// the format requires. Scalars are aligned to their size relative to the buffer start.
+
+// fbTable is a table under construction, indexed by field ID. Entries are fbScalar for
+// inline values, an object (fbTable, fbString, fbTables, fbStructs) for references, or
+// nil for absent fields.
+type fbTable []interface{}
+
+// fbScalar is a little-endian encoded inline value. Its length is also its alignment.
+type fbScalar []byte
+
+// fbString is a string object.
+type fbString string
+
+// fbTables is a vector of tables.
+type fbTables []fbTable
+
+// fbStructs is a vector of 8-byte aligned structs of size bytes each, already encoded.
+type fbStructs struct {
+	size int
+	data []byte
+}
+
+func fbBool(v bool) fbScalar {
+	if v {
+		return fbScalar{1}
+	}
+	return fbScalar{0}
+}
+
+func fbUint8(v uint8) fbScalar { return fbScalar{v} }
+
+func fbInt16(v int16) fbScalar {
+	return binary.LittleEndian.AppendUint16(nil, uint16(v))
+}
+
+func fbInt32(v int32) fbScalar {
+	return binary.LittleEndian.AppendUint32(nil, uint32(v))
+}
+
+func fbInt64(v int64) fbScalar {
+	return binary.LittleEndian.AppendUint64(nil, uint64(v))
+}
+
+// fbBuilder accumulates the encoded buffer.
+type fbBuilder struct {
+	buf []byte
+}
+
+// encodeFlatbuffer encodes root as a complete buffer, padded to a multiple of 8 bytes.
+func encodeFlatbuffer(root fbTable) []byte {
+	b := &fbBuilder{buf: make([]byte, 4, 256)} // Leading uoffset to the root table
+	pos := b.table(root)
+	b.putUint32(0, uint32(pos))
+	b.pad(8)
+	return b.buf
+}
+
+// pad appends zero bytes until the buffer length is a multiple of align.
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *fbBuilder) putUint32(at int, v uint32) {
+	binary.LittleEndian.PutUint32(b.buf[at:], v)
+}
+
+// ref writes obj and stores a uoffset to it at position at.
+func (b *fbBuilder) ref(at int, obj interface{}) {
+	pos := b.object(obj)
+	b.putUint32(at, uint32(pos-at))
+}
+
+// object writes a referenced object and returns its position.
+func (b *fbBuilder) object(obj interface{}) int {
+	switch o := obj.(type) {
+	case fbTable:
+		return b.table(o)
+	case fbString:
+		b.pad(4)
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(o)))
+		b.buf = append(b.buf, o...)
+		b.buf = append(b.buf, 0) // Strings are NUL-terminated
+		return pos
+	case fbTables:
+		b.pad(4)
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(o)))
+		slots := len(b.buf)
+		b.buf = append(b.buf, make([]byte, 4*len(o))...)
+		for i, t := range o {
+			b.ref(slots+4*i, t)
+		}
+		return pos
+	case fbStructs:
+		for (len(b.buf)+4)%8 != 0 { // Elements, after the length, must be 8-byte aligned
+			b.buf = append(b.buf, 0)
+		}
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(o.data)/o.size))
+		b.buf = append(b.buf, o.data...)
+		return pos
+	default:
+		panic("exporter: unsupported flatbuffer object") // Programming error in this package
+	}
+}
+
+// table writes t's vtable followed by t itself and the objects it references, and
+// returns the table's position.
+func (b *fbBuilder) table(t fbTable) int {
+	// Inline layout: the soffset to the vtable, then fields by decreasing size so that
+	// each is naturally aligned (the table itself starts 8-byte aligned).
+	ids := make([]int, 0, len(t))
+	for id, v := range t {
+		if v != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return fieldSize(t[ids[i]]) > fieldSize(t[ids[j]]) })
+	offsets := make([]int, len(t))
+	size := 4
+	for _, id := range ids {
+		n := fieldSize(t[id])
+		for size%n != 0 {
+			size++
+		}
+		offsets[id] = size
+		size += n
+	}
+
+	b.pad(2)
+	vtable := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*len(t)))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	for _, off := range offsets {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(off))
+	}
+
+	b.pad(8)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	b.putUint32(pos, uint32(int32(pos-vtable))) // vtable = table - soffset
+	for _, id := range ids {
+		if s, ok := t[id].(fbScalar); ok {
+			copy(b.buf[pos+offsets[id]:], s)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := t[id].(fbScalar); !ok {
+			b.ref(pos+offsets[id], t[id])
+		}
+	}
+	return pos
+}
+
+// fieldSize returns the inline size of a table field: a scalar's width or a 4-byte uoffset.
+func fieldSize(v interface{}) int {
+	if s, ok := v.(fbScalar); ok {
+		return len(s)
+	}
+	return 4
+}
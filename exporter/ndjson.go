@@ -0,0 +1,59 @@
+// ndjson.go
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// ndjsonWriter writes records as one JSON object per line, keys in column order.
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	cols []column
+	keys [][]byte // JSON-encoded column names with the trailing ':'
+}
+
+func newNDJSONWriter(w io.Writer, cols []column) *ndjsonWriter {
+	nw := &ndjsonWriter{w: bufio.NewWriter(w), cols: cols, keys: make([][]byte, len(cols))}
+	for i, col := range cols {
+		key, _ := json.Marshal(col.name) // Marshalling a string cannot fail
+		nw.keys[i] = append(key, ':')
+	}
+	return nw
+}
+
+func (n *ndjsonWriter) begin() error { return nil }
+
+func (n *ndjsonWriter) writePage(records []nebula.Record) error {
+	for _, rec := range records {
+		n.w.WriteByte('{')
+		for i, col := range n.cols {
+			v, err := value(rec, col)
+			if err != nil {
+				return err
+			}
+			b, err := json.Marshal(v) // []byte (BLOB) marshals as base64, like the API
+			if err != nil {
+				return fmt.Errorf("exporter: record %v: column %q: %w", rec[primaryKey], col.name, err)
+			}
+			if i > 0 {
+				n.w.WriteByte(',')
+			}
+			n.w.Write(n.keys[i])
+			n.w.Write(b)
+		}
+		n.w.WriteString("}\n")
+	}
+	if err := n.w.Flush(); err != nil { // Errors are sticky, so checking once per page suffices
+		return fmt.Errorf("exporter: writing output: %w", err)
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) end() error {
+	return n.w.Flush()
+}
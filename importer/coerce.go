@@ -0,0 +1,135 @@
+// coerce.go
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// columnSet maps column names to their declared types (upper-case, e.g. "INTEGER").
+type columnSet map[string]string
+
+// newColumnSet builds a columnSet from a table schema.
+func newColumnSet(schema *nebula.SchemaPayload) columnSet {
+	cols := make(columnSet, len(schema.Columns))
+	for _, col := range schema.Columns {
+		cols[col.Name] = strings.ToUpper(col.Type)
+	}
+	return cols
+}
+
+// coerceString converts a CSV field to the Go value for a column of colType.
+// An empty field is NULL for every type except TEXT; a field equal to a non-empty
+// nullValue is NULL for every type.
+func coerceString(colType, s string, nullValue string) (interface{}, error) {
+	if (s == "" && colType != "TEXT") || (nullValue != "" && s == nullValue) {
+		return nil, nil
+	}
+	switch colType {
+	case "INTEGER":
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INTEGER %q", s)
+		}
+		return i, nil
+	case "REAL":
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REAL %q", s)
+		}
+		return f, nil
+	case "BOOLEAN":
+		b, err := parseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case "BLOB":
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("invalid BLOB (expected base64): %v", err)
+		}
+		return s, nil
+	default: // TEXT
+		return s, nil
+	}
+}
+
+// coerceJSON converts a decoded NDJSON value (numbers as json.Number) to the Go value
+// for a column of colType.
+func coerceJSON(colType string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch colType {
+	case "INTEGER":
+		switch n := v.(type) {
+		case json.Number:
+			if i, err := n.Int64(); err == nil {
+				return i, nil
+			}
+			if f, err := n.Float64(); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+				return int64(f), nil
+			}
+		case string:
+			return coerceString(colType, n, "")
+		case bool:
+			if n {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		return nil, fmt.Errorf("invalid INTEGER %v", v)
+	case "REAL":
+		switch n := v.(type) {
+		case json.Number:
+			if f, err := n.Float64(); err == nil {
+				return f, nil
+			}
+		case string:
+			return coerceString(colType, n, "")
+		}
+		return nil, fmt.Errorf("invalid REAL %v", v)
+	case "BOOLEAN":
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case json.Number:
+			return parseBool(b.String())
+		case string:
+			return parseBool(b)
+		}
+		return nil, fmt.Errorf("invalid BOOLEAN %v", v)
+	case "BLOB":
+		if s, ok := v.(string); ok {
+			return coerceString(colType, s, "")
+		}
+		return nil, fmt.Errorf("invalid BLOB %v (expected base64 string)", v)
+	default: // TEXT
+		switch t := v.(type) {
+		case string:
+			return t, nil
+		case json.Number:
+			return t.String(), nil
+		case bool:
+			return strconv.FormatBool(t), nil
+		}
+		return nil, fmt.Errorf("invalid TEXT value of type %T", v)
+	}
+}
+
+// parseBool accepts the usual spellings of true and false.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid BOOLEAN %q", s)
+}
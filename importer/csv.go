@@ -0,0 +1,108 @@
+// csv.go
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// ImportCSV imports CSV data from src into a table. The first row must be a header;
+// header fields are mapped to columns by name (or via Options.ColumnMap) and values are
+// coerced to the column types of the table schema. An "id" column is ignored.
+//
+// Rows that fail coercion or are rejected by the server are counted in Result.Rejected
+// and written to Options.RejectWriter. The import stops early, returning the partial
+// Result and an error, on unreadable input or connection/server failures.
+func ImportCSV(ctx context.Context, client *nebula.Client, dbName, tableName string, src io.Reader, opts *Options) (*Result, error) {
+	r, err := newRun(client, dbName, tableName, opts)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := r.fetchColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(src)
+	reader.Comma = r.opts.Comma
+	reader.FieldsPerRecord = -1 // Checked per row so a ragged row is rejected, not fatal
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return &Result{}, nil // Empty input
+		}
+		return nil, fmt.Errorf("importer: reading CSV header: %w", err)
+	}
+
+	targets := make([]string, len(header)) // Column for each field; "" to skip
+	for i, field := range header {
+		if targets[i], err = r.mapField(field, cols); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.opts.RejectWriter != nil {
+		sink := &csvRejects{w: csv.NewWriter(r.opts.RejectWriter)}
+		sink.w.Comma = r.opts.Comma
+		if err := sink.w.Write(append(append([]string{}, header...), "error")); err != nil {
+			return nil, fmt.Errorf("importer: writing reject file: %w", err)
+		}
+		r.rejects = sink
+	}
+
+	var line int64
+	return r.execute(ctx, func() (row, string, bool, error) {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return row{}, "", true, nil
+		}
+		line++
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return row{line: line, source: record}, err.Error(), false, nil
+		}
+		if err != nil {
+			return row{}, "", false, fmt.Errorf("importer: reading CSV: %w", err)
+		}
+
+		rw := row{line: line, source: record, values: make(map[string]interface{}, len(record))}
+		if len(record) != len(header) {
+			return rw, fmt.Sprintf("expected %d fields, got %d", len(header), len(record)), false, nil
+		}
+		for i, field := range record {
+			col := targets[i]
+			if col == "" {
+				continue
+			}
+			v, err := coerceString(cols[col], field, r.opts.NullValue)
+			if err != nil {
+				return rw, fmt.Sprintf("column %s: %v", col, err), false, nil
+			}
+			rw.values[col] = v
+		}
+		if len(rw.values) == 0 {
+			return rw, "row has no importable columns", false, nil
+		}
+		return rw, "", false, nil
+	})
+}
+
+// csvRejects writes rejected rows as CSV with an extra "error" column.
+type csvRejects struct {
+	w *csv.Writer
+}
+
+func (c *csvRejects) reject(r row, reason string) error {
+	fields, _ := r.source.([]string)
+	return c.w.Write(append(append([]string{}, fields...), reason))
+}
+
+func (c *csvRejects) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,320 @@
+// importer.go
+
+// Package importer loads CSV and NDJSON data into a Nebula table. Values are coerced to
+// the table's column types, rows are written in batches with bounded concurrency, and
+// rows that fail are written to an optional reject file together with the reason.
+//
+//	f, _ := os.Open("widgets.csv")
+//	rejects, _ := os.Create("widgets.rejects.csv")
+//	res, err := importer.ImportCSV(ctx, client, "inventory", "widgets", f, &importer.Options{
+//		RejectWriter: rejects,
+//	})
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultConcurrency = 4
+)
+
+// Options specifies optional parameters for ImportCSV and ImportNDJSON.
+// Zero values select the defaults noted on each field.
+type Options struct {
+	// BatchSize is the number of rows written per atomic batch. Default 100.
+	BatchSize int
+
+	// Concurrency is the maximum number of batches written in parallel. Default 4.
+	Concurrency int
+
+	// ColumnMap renames source fields (CSV headers or NDJSON keys) to table columns.
+	// Fields not in the map are matched to columns by name.
+	ColumnMap map[string]string
+
+	// IgnoreUnknownColumns drops source fields that match no table column. Otherwise an
+	// unknown CSV header fails the import before any row is written, and an NDJSON line
+	// with an unknown key is rejected.
+	IgnoreUnknownColumns bool
+
+	// NullValue is a CSV field value that is imported as NULL (e.g., `\N`).
+	// Empty fields are always NULL for non-TEXT columns.
+	NullValue string
+
+	// Comma is the CSV field delimiter. Default ','.
+	Comma rune
+
+	// RejectWriter receives rows that could not be imported, with their error reasons:
+	// as CSV (the source header plus an "error" column) for ImportCSV, or as NDJSON lines
+	// {"line": n, "error": "...", "row": {...}} for ImportNDJSON. Optional.
+	RejectWriter io.Writer
+
+	// Progress, if set, is called after each batch completes. Calls are serialised.
+	Progress func(Progress)
+}
+
+// Progress reports the state of a running import.
+type Progress struct {
+	Read     int64 // Rows read from the source
+	Imported int64 // Rows written to the table
+	Rejected int64 // Rows rejected (coercion or server-side errors)
+}
+
+// Result summarises a finished import. It is returned even when the import stops early.
+type Result Progress
+
+// row is a single source row on its way to the table.
+type row struct {
+	line   int64                  // 1-based line (CSV: data row number, header excluded) in the source
+	source interface{}            // Original row, for the reject file
+	values map[string]interface{} // Coerced column values
+}
+
+// rejectSink writes rejected rows to the reject file.
+type rejectSink interface {
+	reject(r row, reason string) error
+	flush() error
+}
+
+// run is the shared import pipeline: it reads rows from next, batches them and writes
+// the batches with bounded concurrency.
+type run struct {
+	client    *nebula.Client
+	dbName    string
+	tableName string
+	opts      Options
+	rejects   rejectSink
+
+	mu         sync.Mutex
+	progressMu sync.Mutex // Serialises Progress callbacks
+	progress   Progress
+	perRow     bool // Set when the server has no transaction endpoint
+	fatal      error
+}
+
+// newRun validates options and applies defaults.
+func newRun(client *nebula.Client, dbName, tableName string, opts *Options) (*run, error) {
+	if client == nil {
+		return nil, errors.New("importer: client cannot be nil")
+	}
+	r := &run{client: client, dbName: dbName, tableName: tableName}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.BatchSize <= 0 {
+		r.opts.BatchSize = defaultBatchSize
+	}
+	if r.opts.Concurrency <= 0 {
+		r.opts.Concurrency = defaultConcurrency
+	}
+	if r.opts.Comma == 0 {
+		r.opts.Comma = ','
+	}
+	return r, nil
+}
+
+// fetchColumns loads the target table's schema.
+func (r *run) fetchColumns(ctx context.Context) (columnSet, error) {
+	schema, err := r.client.Tables.GetSchema(ctx, r.dbName, r.tableName)
+	if err != nil {
+		return nil, fmt.Errorf("importer: fetching schema of %s.%s: %w", r.dbName, r.tableName, err)
+	}
+	return newColumnSet(schema), nil
+}
+
+// mapField resolves a source field name to a table column ("" if it maps to none).
+func (r *run) mapField(field string, cols columnSet) (string, error) {
+	col := field
+	if mapped, ok := r.opts.ColumnMap[field]; ok {
+		col = mapped
+	}
+	if col == "id" {
+		return "", nil // The primary key is assigned by the server, even if the schema lists it
+	}
+	if _, ok := cols[col]; ok {
+		return col, nil
+	}
+	if r.opts.IgnoreUnknownColumns {
+		return "", nil
+	}
+	return "", fmt.Errorf("importer: field %q matches no column of table %s", field, r.tableName)
+}
+
+// execute reads rows from next until it returns done, writing them in batches.
+// next returns a row, or a row with an error reason to reject it.
+func (r *run) execute(ctx context.Context, next func() (row, string, bool, error)) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan []row, r.opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if err := r.writeBatch(ctx, batch); err != nil {
+					r.setFatal(err)
+					cancel()
+				}
+			}
+		}()
+	}
+
+	readErr := func() error {
+		batch := make([]row, 0, r.opts.BatchSize)
+		for {
+			rw, reason, done, err := next()
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+			r.count(func(p *Progress) { p.Read++ })
+			if reason != "" {
+				if err := r.reject(rw, reason); err != nil {
+					return err
+				}
+				continue
+			}
+			batch = append(batch, rw)
+			if len(batch) == r.opts.BatchSize {
+				select {
+				case batches <- batch:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				batch = make([]row, 0, r.opts.BatchSize)
+			}
+		}
+		if len(batch) > 0 {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}()
+	close(batches)
+	wg.Wait()
+
+	if r.rejects != nil {
+		if err := r.rejects.flush(); err != nil && readErr == nil {
+			readErr = fmt.Errorf("importer: writing reject file: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := Result(r.progress)
+	if r.fatal != nil {
+		return &res, r.fatal
+	}
+	if readErr != nil {
+		return &res, readErr
+	}
+	return &res, nil
+}
+
+// writeBatch writes a batch atomically in a transaction. If the batch is rejected
+// because of its content, rows are retried one by one so only the bad rows are rejected.
+func (r *run) writeBatch(ctx context.Context, batch []row) error {
+	r.mu.Lock()
+	perRow := r.perRow
+	r.mu.Unlock()
+
+	if !perRow {
+		err := r.client.Transaction(ctx, r.dbName, func(tx *nebula.Tx) error {
+			for _, rw := range batch {
+				tx.Create(r.tableName, rw.values)
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+			r.count(func(p *Progress) { p.Imported += int64(len(batch)) })
+			r.reportProgress()
+			return nil
+		case errors.Is(err, nebula.ErrUnsupportedByServer):
+			r.mu.Lock()
+			r.perRow = true
+			r.mu.Unlock()
+		case !rowError(err):
+			return fmt.Errorf("importer: writing batch: %w", err)
+		}
+	}
+
+	for _, rw := range batch {
+		_, err := r.client.Records.Create(ctx, r.dbName, r.tableName, rw.values)
+		switch {
+		case err == nil:
+			r.count(func(p *Progress) { p.Imported++ })
+		case rowError(err):
+			if err := r.reject(rw, err.Error()); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("importer: writing row %d: %w", rw.line, err)
+		}
+	}
+	r.reportProgress()
+	return nil
+}
+
+// rowError reports whether err is caused by the row's content (so the row is rejected
+// and the import continues) rather than by the connection or server.
+func rowError(err error) bool {
+	return errors.Is(err, nebula.ErrValidation) || errors.Is(err, nebula.ErrBadRequest) || errors.Is(err, nebula.ErrConflict)
+}
+
+// reject records a rejected row and writes it to the reject file, if any.
+func (r *run) reject(rw row, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.Rejected++
+	if r.rejects == nil {
+		return nil
+	}
+	if err := r.rejects.reject(rw, reason); err != nil {
+		return fmt.Errorf("importer: writing reject file: %w", err)
+	}
+	return nil
+}
+
+// count applies fn to the progress counters under the lock.
+func (r *run) count(fn func(*Progress)) {
+	r.mu.Lock()
+	fn(&r.progress)
+	r.mu.Unlock()
+}
+
+// reportProgress calls the Progress callback with a snapshot of the counters.
+func (r *run) reportProgress() {
+	if r.opts.Progress == nil {
+		return
+	}
+	r.progressMu.Lock()
+	defer r.progressMu.Unlock()
+	r.mu.Lock()
+	p := r.progress
+	r.mu.Unlock()
+	r.opts.Progress(p)
+}
+
+// setFatal records the first fatal error.
+func (r *run) setFatal(err error) {
+	r.mu.Lock()
+	if r.fatal == nil {
+		r.fatal = err
+	}
+	r.mu.Unlock()
+}
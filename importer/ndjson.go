@@ -0,0 +1,114 @@
+// ndjson.go
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+const maxNDJSONLine = 16 * 1024 * 1024 // Longest accepted NDJSON line
+
+// ImportNDJSON imports newline-delimited JSON objects from src into a table, one record
+// per line. Keys are mapped to columns by name (or via Options.ColumnMap) and values are
+// coerced to the column types of the table schema. Blank lines and an "id" key are ignored.
+//
+// Rejected lines are counted in Result.Rejected and written to Options.RejectWriter as
+// {"line": n, "error": "...", "row": <original line>}. See ImportCSV for when the import stops early.
+func ImportNDJSON(ctx context.Context, client *nebula.Client, dbName, tableName string, src io.Reader, opts *Options) (*Result, error) {
+	r, err := newRun(client, dbName, tableName, opts)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := r.fetchColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.RejectWriter != nil {
+		r.rejects = &ndjsonRejects{w: bufio.NewWriter(r.opts.RejectWriter)}
+	}
+
+	targets := make(map[string]string) // Memoised field -> column mapping ("" to skip)
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+
+	var line int64
+	return r.execute(ctx, func() (row, string, bool, error) {
+		for {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return row{}, "", false, fmt.Errorf("importer: reading NDJSON: %w", err)
+				}
+				return row{}, "", true, nil
+			}
+			line++
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue // Blank line
+			}
+			source := json.RawMessage(append([]byte(nil), raw...))
+
+			var obj map[string]interface{}
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			if err := dec.Decode(&obj); err != nil || obj == nil {
+				return row{line: line, source: source}, "line is not a JSON object", false, nil
+			}
+
+			rw := row{line: line, source: source, values: make(map[string]interface{}, len(obj))}
+			for field, value := range obj {
+				col, ok := targets[field]
+				if !ok {
+					var err error
+					if col, err = r.mapField(field, cols); err != nil {
+						return rw, err.Error(), false, nil
+					}
+					targets[field] = col
+				}
+				if col == "" {
+					continue
+				}
+				v, err := coerceJSON(cols[col], value)
+				if err != nil {
+					return rw, fmt.Sprintf("column %s: %v", col, err), false, nil
+				}
+				rw.values[col] = v
+			}
+			if len(rw.values) == 0 {
+				return rw, "row has no importable columns", false, nil
+			}
+			return rw, "", false, nil
+		}
+	})
+}
+
+// ndjsonRejects writes rejected lines as NDJSON objects.
+type ndjsonRejects struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonRejects) reject(r row, reason string) error {
+	entry := struct {
+		Line  int64       `json:"line"`
+		Error string      `json:"error"`
+		Row   interface{} `json:"row"`
+	}{r.line, reason, r.source}
+	if raw, ok := r.source.(json.RawMessage); ok && !json.Valid(raw) {
+		entry.Row = string(raw) // Keep unparseable lines verbatim
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = n.w.Write(append(b, '\n'))
+	return err
+}
+
+func (n *ndjsonRejects) flush() error {
+	return n.w.Flush()
+}
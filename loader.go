@@ -0,0 +1,290 @@
+// loader.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultLoaderWait        = 2 * time.Millisecond // Collection window before a batch is fetched
+	defaultLoaderMaxBatch    = 100                  // IDs per batched fetch
+	defaultLoaderParallelism = 8                    // Concurrent Gets when batched fetches are unsupported
+)
+
+// GetMany retrieves the records with the given IDs in a single request, returning them
+// keyed by ID. IDs that don't exist are absent from the map. Servers without the batch
+// capability fail with ErrUnsupportedByServer; RecordLoader falls back to Get for them.
+func (s *RecordService) GetMany(ctx context.Context, dbName, tableName string, recordIDs []int64, reqOpts ...RequestOption) (map[int64]Record, error) {
+	apiPath, err := s.buildRecordPath("Records.GetMany", dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.client.requireCapability("Records.GetMany", CapabilityBatch); err != nil {
+		return nil, err
+	}
+	if len(recordIDs) == 0 {
+		return make(map[int64]Record), nil
+	}
+	ids := make([]string, len(recordIDs))
+	for i, id := range recordIDs {
+		if id <= 0 {
+			return nil, (fieldErrors{{Field: fmt.Sprintf("record_ids[%d]", i), Code: FieldCodeInvalid, Message: "record ID must be positive"}}).err("Records.GetMany")
+		}
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	apiPath += "/batch?" + url.Values{"ids": {strings.Join(ids, ",")}}.Encode()
+
+	var result []Record // Expecting a JSON array of the records found
+	err = s.client.doRequest(ctx, "Records.GetMany", http.MethodGet, apiPath, nil, &result, reqOpts...)
+	if err != nil {
+		// Handles 400 (bad IDs), 401, 404 (db/table not found, or no batch endpoint), 500
+		return nil, unsupportedByServer("Records.GetMany", CapabilityBatch, err)
+	}
+
+	if err := s.client.typeRecords(ctx, "Records.GetMany", dbName, tableName, result...); err != nil {
+		return nil, err
+	}
+	records := make(map[int64]Record, len(result))
+	for _, record := range result {
+		id, err := record.Int64("id")
+		if err != nil {
+			return nil, fmt.Errorf("%w: batch record without a valid id: %w", ErrInvalidResponse, err)
+		}
+		records[id] = record
+	}
+	return records, nil
+}
+
+// LoaderOptions configures a RecordLoader. Zero values select the defaults.
+type LoaderOptions struct {
+	Wait        time.Duration // How long to collect Load calls before fetching (default 2ms)
+	MaxBatch    int           // Most IDs per fetch; a full batch is fetched at once (default 100)
+	Parallelism int           // Concurrent Gets for servers without GetMany (default 8)
+}
+
+// RecordLoader coalesces concurrent RecordService.Get calls, e.g. from GraphQL
+// resolvers. Load calls for the same table arriving within a short window are
+// deduplicated and fetched together with a single GetMany; if the server doesn't
+// support it, the loader switches to Gets with bounded parallelism. A Load for an ID
+// that is already being fetched joins that fetch. Nothing is cached once a fetch
+// completes; each caller receives its own copy of the record.
+//
+//	loader := nebula.NewRecordLoader(client, nil)
+//	author, err := loader.Load(ctx, "blog", "authors", post.AuthorID)
+type RecordLoader struct {
+	client *Client
+	opts   LoaderOptions
+
+	batchUnsupported atomic.Bool // Set once the server is known to lack GetMany
+
+	mu      sync.Mutex
+	pending map[loaderKey]*loaderBatch          // Batch being collected, per table
+	calls   map[loaderKey]map[int64]*loaderCall // Requested or in-flight IDs, per table
+}
+
+// loaderKey identifies a table.
+type loaderKey struct {
+	dbName, tableName string
+}
+
+// loaderBatch collects the IDs of one table until it is fetched.
+type loaderBatch struct {
+	ids   []int64
+	ctx   context.Context // Context of the first Load, without its cancellation
+	timer *time.Timer
+}
+
+// loaderCall is the shared result of one ID's fetch.
+type loaderCall struct {
+	done   chan struct{} // Closed when record/err are set
+	record Record
+	err    error
+}
+
+// NewRecordLoader creates a RecordLoader for client. opts may be nil.
+func NewRecordLoader(client *Client, opts *LoaderOptions) *RecordLoader {
+	l := &RecordLoader{
+		client:  client,
+		pending: make(map[loaderKey]*loaderBatch),
+		calls:   make(map[loaderKey]map[int64]*loaderCall),
+	}
+	if opts != nil {
+		l.opts = *opts
+	}
+	if l.opts.Wait <= 0 {
+		l.opts.Wait = defaultLoaderWait
+	}
+	if l.opts.MaxBatch <= 0 {
+		l.opts.MaxBatch = defaultLoaderMaxBatch
+	}
+	if l.opts.Parallelism <= 0 {
+		l.opts.Parallelism = defaultLoaderParallelism
+	}
+	return l
+}
+
+// Load returns the record with the given ID, like RecordService.Get, batching the
+// fetch with concurrent Loads. It returns ErrRecordNotFound if the record doesn't exist.
+// Cancelling ctx abandons the wait; the shared fetch continues for other callers.
+//
+// A batch is fetched with the values of the ctx passed to its first Load, without its
+// cancellation or deadline; the contexts of later Loads joining the batch only bound
+// their own wait.
+func (l *RecordLoader) Load(ctx context.Context, dbName, tableName string, recordID int64) (Record, error) {
+	if _, err := l.client.recordPath("RecordLoader.Load", dbName, tableName, recordID); err != nil {
+		return nil, err
+	}
+	call := l.enqueue(ctx, loaderKey{dbName, tableName}, recordID)
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	record := make(Record, len(call.record))
+	for k, v := range call.record {
+		record[k] = v
+	}
+	return record, nil
+}
+
+// LoadMany loads several records of one table, returning them in the order of
+// recordIDs with a per-ID error (nil on success).
+func (l *RecordLoader) LoadMany(ctx context.Context, dbName, tableName string, recordIDs []int64) ([]Record, []error) {
+	records := make([]Record, len(recordIDs))
+	errs := make([]error, len(recordIDs))
+	var wg sync.WaitGroup
+	for i, id := range recordIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			records[i], errs[i] = l.Load(ctx, dbName, tableName, id)
+		}()
+	}
+	wg.Wait()
+	return records, errs
+}
+
+// enqueue returns the call for id, adding id to the table's pending batch unless it
+// is already requested.
+func (l *RecordLoader) enqueue(ctx context.Context, key loaderKey, id int64) *loaderCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	calls := l.calls[key]
+	if calls == nil {
+		calls = make(map[int64]*loaderCall)
+		l.calls[key] = calls
+	}
+	if call, ok := calls[id]; ok {
+		return call // Deduplicated: pending or in flight
+	}
+	call := &loaderCall{done: make(chan struct{})}
+	calls[id] = call
+
+	batch := l.pending[key]
+	if batch == nil {
+		batch = &loaderBatch{ctx: context.WithoutCancel(ctx)}
+		batch.timer = time.AfterFunc(l.opts.Wait, func() { l.dispatch(key, batch) })
+		l.pending[key] = batch
+	}
+	batch.ids = append(batch.ids, id)
+	if len(batch.ids) >= l.opts.MaxBatch {
+		batch.timer.Stop()
+		delete(l.pending, key)
+		go l.fetch(key, batch)
+	}
+	return call
+}
+
+// dispatch fetches batch when its collection window ends, unless it was already
+// dispatched for being full.
+func (l *RecordLoader) dispatch(key loaderKey, batch *loaderBatch) {
+	l.mu.Lock()
+	if l.pending[key] != batch {
+		l.mu.Unlock()
+		return
+	}
+	delete(l.pending, key)
+	l.mu.Unlock()
+	l.fetch(key, batch)
+}
+
+// fetch loads batch's records and completes their calls.
+func (l *RecordLoader) fetch(key loaderKey, batch *loaderBatch) {
+	if !l.batchUnsupported.Load() {
+		records, err := l.client.Records.GetMany(batch.ctx, key.dbName, key.tableName, batch.ids)
+		if err == nil {
+			for _, id := range batch.ids {
+				if record, ok := records[id]; ok {
+					l.complete(key, id, record, nil)
+				} else {
+					l.complete(key, id, nil, fmt.Errorf("record %d not found in %s.%s: %w", id, key.dbName, key.tableName, ErrRecordNotFound))
+				}
+			}
+			return
+		}
+		if !errors.Is(err, ErrUnsupportedByServer) {
+			for _, id := range batch.ids {
+				l.complete(key, id, nil, err)
+			}
+			return
+		}
+		if l.batchEndpointMissing(err) {
+			l.batchUnsupported.Store(true)
+		} // Otherwise a bare 404: fall back for this batch only, as the table may be missing
+	}
+
+	sem := make(chan struct{}, l.opts.Parallelism)
+	var wg sync.WaitGroup
+	for _, id := range batch.ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			record, err := l.client.Records.Get(batch.ctx, key.dbName, key.tableName, id)
+			l.complete(key, id, record, err)
+		}()
+	}
+	wg.Wait()
+}
+
+// batchEndpointMissing reports whether err, an ErrUnsupportedByServer from GetMany,
+// shows for certain that the server has no batch endpoint: ServerInfo says so, or the
+// server rejected the method. A 404 without an error code may also come from a server
+// that reports missing tables without a code.
+func (l *RecordLoader) batchEndpointMissing(err error) bool {
+	if l.client.lacksCapability(CapabilityBatch) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode != http.StatusNotFound
+}
+
+// complete delivers the result for id to its callers and forgets the call, so later
+// Loads fetch the record afresh.
+func (l *RecordLoader) complete(key loaderKey, id int64, record Record, err error) {
+	l.mu.Lock()
+	call := l.calls[key][id]
+	delete(l.calls[key], id)
+	if len(l.calls[key]) == 0 {
+		delete(l.calls, key)
+	}
+	l.mu.Unlock()
+	if call == nil {
+		return
+	}
+	call.record, call.err = record, err
+	close(call.done)
+}
@@ -0,0 +1,174 @@
+// loader_test.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loaderServer serves records 1 to 9 of a table, one at a time and, unless batchStatus
+// is set, in batches. It records the ID lists of batch requests.
+type loaderServer struct {
+	batchStatus int // Status answered to batch requests, 0 to serve them
+
+	mu          sync.Mutex
+	batches     [][]int64
+	gets        int
+	active, max int // Concurrent single-record Gets
+}
+
+func (s *loaderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/records/batch") {
+		var ids []int64
+		for _, field := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			id, _ := strconv.ParseInt(field, 10, 64)
+			ids = append(ids, id)
+		}
+		s.mu.Lock()
+		s.batches = append(s.batches, ids)
+		s.mu.Unlock()
+		if s.batchStatus != 0 {
+			w.WriteHeader(s.batchStatus)
+			w.Write([]byte(`{"error": "no batch endpoint"}`))
+			return
+		}
+		var found []string
+		for _, id := range ids {
+			if id < 10 {
+				found = append(found, fmt.Sprintf(`{"id": %d}`, id))
+			}
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(found, ","))
+		return
+	}
+
+	s.mu.Lock()
+	s.gets++
+	s.active++
+	s.max = max(s.max, s.active)
+	s.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+	id, _ := strconv.ParseInt(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], 10, 64)
+	if id >= 10 {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "record not found"}`))
+		return
+	}
+	fmt.Fprintf(w, `{"id": %d}`, id)
+}
+
+func newLoaderTest(t *testing.T, backend *loaderServer) *Client {
+	t.Helper()
+	srv := httptest.NewServer(backend)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	return client
+}
+
+// checkLoaded checks the results of LoadMany: each record has its requested ID, and
+// IDs from 10 up fail with ErrRecordNotFound.
+func checkLoaded(t *testing.T, ids []int64, records []Record, errs []error) {
+	t.Helper()
+	for i, id := range ids {
+		if id >= 10 {
+			if !errors.Is(errs[i], ErrRecordNotFound) {
+				t.Errorf("Load(%d) err = %v, want ErrRecordNotFound", id, errs[i])
+			}
+			continue
+		}
+		if got, err := records[i].Int64("id"); errs[i] != nil || err != nil || got != id {
+			t.Errorf("Load(%d) = %v, %v", id, records[i], errs[i])
+		}
+	}
+}
+
+func TestRecordLoaderBatches(t *testing.T) {
+	backend := &loaderServer{}
+	loader := NewRecordLoader(newLoaderTest(t, backend), &LoaderOptions{Wait: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	ids := []int64{1, 2, 2, 12, 1, 3}
+	records, errs := loader.LoadMany(ctx, "shop", "items", ids)
+	checkLoaded(t, ids, records, errs)
+	if len(backend.batches) != 1 || backend.gets != 0 {
+		t.Fatalf("LoadMany sent batches %v and %d Gets, want one batch", backend.batches, backend.gets)
+	}
+	if got := slices.Sorted(slices.Values(backend.batches[0])); !slices.Equal(got, []int64{1, 2, 3, 12}) {
+		t.Errorf("batch IDs = %v, want each ID once", backend.batches[0])
+	}
+	records[1]["id"] = "changed"
+	if records[2]["id"] == "changed" {
+		t.Error("callers loading the same ID share a record")
+	}
+
+	// Full batches are fetched without waiting for the window
+	backend.batches = nil
+	loader = NewRecordLoader(loader.client, &LoaderOptions{Wait: time.Hour, MaxBatch: 2})
+	ids = []int64{1, 2, 3, 4}
+	records, errs = loader.LoadMany(ctx, "shop", "items", ids)
+	checkLoaded(t, ids, records, errs)
+	if len(backend.batches) != 2 || len(backend.batches[0]) != 2 || len(backend.batches[1]) != 2 {
+		t.Errorf("batches = %v, want two of two IDs", backend.batches)
+	}
+}
+
+func TestRecordLoaderFallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		wantBatches int // Batch requests after two rounds of loads
+	}{
+		{"no batch endpoint", http.StatusMethodNotAllowed, 1},
+		{"bare 404", http.StatusNotFound, 2}, // Might be a missing table: keep trying
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &loaderServer{batchStatus: tt.status}
+			loader := NewRecordLoader(newLoaderTest(t, backend), &LoaderOptions{Wait: 20 * time.Millisecond, Parallelism: 2})
+			ids := []int64{1, 2, 3, 4, 5, 11}
+			for range 2 {
+				records, errs := loader.LoadMany(context.Background(), "shop", "items", ids)
+				checkLoaded(t, ids, records, errs)
+			}
+			if len(backend.batches) != tt.wantBatches {
+				t.Errorf("%d batch requests, want %d", len(backend.batches), tt.wantBatches)
+			}
+			if backend.gets != 2*len(ids) || backend.max > 2 {
+				t.Errorf("%d Gets with up to %d at once, want %d with at most 2", backend.gets, backend.max, 2*len(ids))
+			}
+		})
+	}
+}
+
+func TestRecordLoaderCancel(t *testing.T) {
+	backend := &loaderServer{}
+	loader := NewRecordLoader(newLoaderTest(t, backend), &LoaderOptions{Wait: 50 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := loader.Load(ctx, "shop", "items", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Load with a cancelled context = %v", err)
+	}
+	// The abandoned fetch still completes for a caller joining it
+	if rec, err := loader.Load(context.Background(), "shop", "items", 1); err != nil || rec["id"] == nil {
+		t.Errorf("Load joining an abandoned fetch = %v, %v", rec, err)
+	}
+	if len(backend.batches) != 1 {
+		t.Errorf("%d batch requests, want 1", len(backend.batches))
+	}
+}
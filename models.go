@@ -1,6 +1,8 @@
 // models.go
 package nebula
 
+import "encoding/json"
+
 // --- Auth Models ---
 
 // SignupPayload defines the structure for the signup request body.
@@ -59,27 +61,49 @@ type CreateRecordResponse struct {
 }
 
 // UpdateRecordResponse defines the structure for the update record success response.
-// Note: The SDK's Update method currently just returns error, but if needed,
-// it could return this struct parsed from the response body.
+// Note: The SDK's Update method just returns error; UpdateWithResult parses this struct.
 type UpdateRecordResponse struct {
 	Message      string `json:"message"`
 	RecordID     int64  `json:"record_id"`
 	RowsAffected int64  `json:"rows_affected"`
+	Record       Record `json:"record,omitempty"` // Present when requested with return=record
+}
+
+// UpdateOptions specifies optional behaviour for RecordService.UpdateWithResult.
+type UpdateOptions struct {
+	// ReturnRecord requests the full record as it is after the update.
+	ReturnRecord bool
 }
 
-// Note: For ListRecords and GetRecord, the API returns []map[string]interface{}
-// or map[string]interface{}, so no specific SDK structs are strictly needed
-// for the record data itself, unless you want to provide helpers for
-// unmarshaling into user-defined structs later.
+// UpdateResult is returned by RecordService.UpdateWithResult.
+type UpdateResult struct {
+	RecordID     int64  // ID of the updated record
+	RowsAffected int64  // 0 if the update matched no row or changed nothing
+	Record       Record // The record after the update; nil unless UpdateOptions.ReturnRecord was set
+}
+
+// BulkWriteResponse defines the structure for the UpdateWhere/DeleteWhere success response.
+type BulkWriteResponse struct {
+	Message      string `json:"message"`
+	RowsAffected int64  `json:"rows_affected"`
+}
+
+// Note: For ListRecords and GetRecord, the API returns a JSON array of objects
+// or a single object; these are decoded into Record (see recordvalue.go).
 
 // --- *** NEW: Options for Listing Records *** ---
 
+// Filter selects records by simple equality on column values (e.g., {"status":"active", "priority":"1"}).
+// The same filter model is used by List, Stream, Count and Aggregate.
+// Values are strings; the backend converts them based on the column type.
+type Filter map[string]string
+
 // ListRecordsOptions specifies optional parameters for listing records.
 // Uses pointers to distinguish between zero values and unset parameters.
 type ListRecordsOptions struct {
 	// Filters apply simple equality checks (e.g., {"status":"active", "priority":"1"}).
 	// Backend validates keys and converts values based on schema.
-	Filters map[string]string
+	Filters Filter
 
 	// Limit the number of records returned (for pagination). Backend support required.
 	Limit *int
@@ -94,7 +118,145 @@ type ListRecordsOptions struct {
 	SortDirection *string // "asc" or "desc"
 }
 
+// --- Transaction Models ---
+
+// TxOperation is a single operation in a transaction batch.
+// ID and record data values may contain *TxResult placeholders, encoded as {"$ref": index}.
+type TxOperation struct {
+	Op    string                 `json:"op"` // "create", "update", "delete" or "get"
+	Table string                 `json:"table"`
+	ID    TxRecordID             `json:"id,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// TransactionPayload defines the structure for the transaction request body.
+type TransactionPayload struct {
+	Operations []TxOperation `json:"operations"`
+}
+
+// TxOperationResult is the server's result for one operation of a committed transaction.
+type TxOperationResult struct {
+	RecordID     int64  `json:"record_id"`
+	RowsAffected int64  `json:"rows_affected"`
+	Record       Record `json:"record,omitempty"` // Present for "get" operations
+}
+
+// TransactionResponse defines the structure for the transaction success response.
+type TransactionResponse struct {
+	Results []TxOperationResult `json:"results"`
+}
+
+// --- Aggregate Models ---
+
+// AggregateFunc is an aggregate function supported by RecordService.Aggregate.
+type AggregateFunc string
+
+// Supported aggregate functions.
+const (
+	AggregateCount AggregateFunc = "COUNT"
+	AggregateSum   AggregateFunc = "SUM"
+	AggregateAvg   AggregateFunc = "AVG"
+	AggregateMin   AggregateFunc = "MIN"
+	AggregateMax   AggregateFunc = "MAX"
+)
+
+// Aggregation is a single aggregate expression, e.g. SUM(quantity) AS total.
+type Aggregation struct {
+	Func   AggregateFunc `json:"func"`
+	Column string        `json:"column,omitempty"` // Column to aggregate; may be empty for COUNT (counts rows)
+	Alias  string        `json:"alias"`            // Result key; defaults to e.g. "count" or "sum_quantity"
+}
+
+// AggregateQuery describes an aggregate request: the aggregations to compute,
+// the columns to group by (optional) and the records to include (optional Filter).
+type AggregateQuery struct {
+	Aggregations []Aggregation `json:"aggregations"`
+	GroupBy      []string      `json:"group_by,omitempty"`
+	Filter       Filter        `json:"filters,omitempty"`
+}
+
+// AggregateRow is one result row of an aggregate query.
+// Group holds the group-by column values (empty without GROUP BY) and Values holds
+// one entry per aggregation keyed by alias; use the Record accessors to read them typed.
+type AggregateRow struct {
+	Group  Record `json:"group"`
+	Values Record `json:"values"`
+}
+
+// AggregateResponse defines the structure for the aggregate records response.
+type AggregateResponse struct {
+	Rows []AggregateRow `json:"rows"`
+}
+
+// CountRecordsResponse defines the structure for the count records response.
+type CountRecordsResponse struct {
+	Count int64 `json:"count"`
+}
+
+// --- Server Models ---
+
+// ServerInfo describes a Nebula server, as returned by Client.ServerInfo.
+type ServerInfo struct {
+	Version      string       `json:"version"`      // Server release (e.g., "1.4.0"); empty for servers predating the info endpoint
+	APIVersion   string       `json:"api_version"`  // API version (e.g., "v1")
+	Capabilities []Capability `json:"capabilities"` // Optional features the server supports
+}
+
+// Supports reports whether the server has capability c.
+func (i *ServerInfo) Supports(c Capability) bool {
+	for _, have := range i.Capabilities {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
 // ErrorResponse defines the standard JSON error structure returned by the API.
+// Both the simple form `{"error": "message"}` and the structured form
+// `{"error": {"code": "...", "message": "...", "details": [...]}}` (or the same
+// fields at the top level) are accepted when unmarshaling.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error   string       `json:"error"`
+	Code    string       `json:"code,omitempty"`    // Optional machine-readable code (e.g., "TABLE_NOT_FOUND")
+	Details []FieldError `json:"details,omitempty"` // Optional per-field validation details
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting both the simple and structured error forms.
+func (r *ErrorResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Code    string          `json:"code"`
+		Details []FieldError    `json:"details"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Error, r.Code, r.Details = raw.Message, raw.Code, raw.Details
+
+	if len(raw.Error) == 0 || string(raw.Error) == "null" {
+		return nil
+	}
+	var msg string
+	if err := json.Unmarshal(raw.Error, &msg); err == nil {
+		r.Error = msg // Simple form
+		return nil
+	}
+	var nested struct {
+		Code    string       `json:"code"`
+		Message string       `json:"message"`
+		Details []FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(raw.Error, &nested); err != nil {
+		return err
+	}
+	r.Error = nested.Message
+	if nested.Code != "" {
+		r.Code = nested.Code
+	}
+	if nested.Details != nil {
+		r.Details = nested.Details
+	}
+	return nil
 }
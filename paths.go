@@ -0,0 +1,96 @@
+// paths.go
+package nebula
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxIdentifierLength = 64 // Longest database/table name accepted by the Nebula backend
+
+// databaseNamePattern matches database names accepted by the Nebula backend.
+var databaseNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// validateDatabaseName checks dbName against the server's naming rules.
+func validateDatabaseName(dbName string) fieldErrors {
+	var errs fieldErrors
+	switch {
+	case strings.TrimSpace(dbName) == "":
+		errs.add("db_name", FieldCodeRequired, "database name cannot be empty")
+	case len(dbName) > maxIdentifierLength:
+		errs.add("db_name", FieldCodeInvalid, "database name %q exceeds %d characters", dbName, maxIdentifierLength)
+	case !databaseNamePattern.MatchString(dbName):
+		errs.add("db_name", FieldCodeInvalid, "database name %q must start with a letter or underscore and contain only letters, digits, underscores and hyphens", dbName)
+	}
+	return errs
+}
+
+// validateTableName checks tableName against the server's naming rules.
+func validateTableName(tableName string) fieldErrors {
+	var errs fieldErrors
+	switch {
+	case strings.TrimSpace(tableName) == "":
+		errs.add("table_name", FieldCodeRequired, "table name cannot be empty")
+	case len(tableName) > maxIdentifierLength:
+		errs.add("table_name", FieldCodeInvalid, "table name %q exceeds %d characters", tableName, maxIdentifierLength)
+	case !identifierPattern.MatchString(tableName):
+		errs.add("table_name", FieldCodeInvalid, "table name %q must start with a letter or underscore and contain only letters, digits and underscores", tableName)
+	}
+	return errs
+}
+
+// apiPath builds an API path under apiVersionPath from raw (unescaped) segments,
+// escaping each one with url.PathEscape so names can never alter the path structure
+// or inject a query string.
+func (c *Client) apiPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, seg := range segments {
+		escaped[i] = url.PathEscape(seg)
+	}
+	return c.getAPIPath(strings.Join(escaped, "/"))
+}
+
+// databasePath validates dbName for op and returns the path
+// databases/{dbName}[/rest...].
+func (c *Client) databasePath(op, dbName string, rest ...string) (string, error) {
+	if err := validateDatabaseName(dbName).err(op); err != nil {
+		return "", err
+	}
+	return c.apiPath(append([]string{"databases", dbName}, rest...)...), nil
+}
+
+// tablePath validates dbName and tableName for op and returns the path
+// databases/{dbName}/tables/{tableName}[/rest...].
+func (c *Client) tablePath(op, dbName, tableName string, rest ...string) (string, error) {
+	errs := append(validateDatabaseName(dbName), validateTableName(tableName)...)
+	if err := errs.err(op); err != nil {
+		return "", err
+	}
+	return c.apiPath(append([]string{"databases", dbName, "tables", tableName}, rest...)...), nil
+}
+
+// recordPath validates its arguments for op and returns the path
+// databases/{dbName}/tables/{tableName}/records/{recordID}.
+func (c *Client) recordPath(op, dbName, tableName string, recordID int64) (string, error) {
+	if recordID <= 0 {
+		return "", (fieldErrors{{Field: "record_id", Code: FieldCodeInvalid, Message: "record ID must be positive"}}).err(op)
+	}
+	return c.tablePath(op, dbName, tableName, "records", strconv.FormatInt(recordID, 10))
+}
+
+// resolveURL joins an escaped API path (optionally with a query string) onto the
+// base URL with url.JoinPath semantics, preserving any path prefix in the base URL
+// (e.g., a reverse proxy mounting Nebula at /nebula/).
+func (c *Client) resolveURL(apiPath string) string {
+	return joinAPIPath(c.baseURL, apiPath).String()
+}
+
+// joinAPIPath resolves apiPath against base as described for resolveURL.
+func joinAPIPath(base *url.URL, apiPath string) *url.URL {
+	pathPart, query, _ := strings.Cut(apiPath, "?")
+	u := base.JoinPath(strings.TrimPrefix(pathPart, "/"))
+	u.RawQuery = query
+	return u
+}
@@ -3,6 +3,7 @@ package nebula
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -17,25 +18,15 @@ type RecordService struct {
 	client *Client // Reference back to the main client
 }
 
-// buildRecordPath constructs the base API path for record operations.
-func (s *RecordService) buildRecordPath(dbName, tableName string) (string, error) {
-	if strings.TrimSpace(dbName) == "" || strings.TrimSpace(tableName) == "" {
-		return "", errors.New("database name and table name cannot be empty")
-	}
-	// Future: consider url.PathEscape for dbName and tableName if they can contain special chars
-	return s.client.getAPIPath(fmt.Sprintf("databases/%s/tables/%s/records", dbName, tableName)), nil
+// buildRecordPath constructs the base API path for record operations,
+// validating and escaping the database and table names for op.
+func (s *RecordService) buildRecordPath(op, dbName, tableName string) (string, error) {
+	return s.client.tablePath(op, dbName, tableName, "records")
 }
 
 // buildSingleRecordPath constructs the API path for operations on a specific record.
-func (s *RecordService) buildSingleRecordPath(dbName, tableName string, recordID int64) (string, error) {
-	basePath, err := s.buildRecordPath(dbName, tableName)
-	if err != nil {
-		return "", err
-	}
-	if recordID <= 0 {
-		return "", errors.New("record ID must be positive")
-	}
-	return fmt.Sprintf("%s/%d", basePath, recordID), nil
+func (s *RecordService) buildSingleRecordPath(op, dbName, tableName string, recordID int64) (string, error) {
+	return s.client.recordPath(op, dbName, tableName, recordID)
 }
 
 // Create inserts a new record into the specified table.
@@ -45,13 +36,16 @@ func (s *RecordService) Create(ctx context.Context, dbName, tableName string, re
 	if len(recordData) == 0 {
 		return 0, errors.New("record data cannot be empty")
 	}
-	apiPath, err := s.buildRecordPath(dbName, tableName)
+	if err := validateRecordData(recordData).err("Records.Create"); err != nil {
+		return 0, err
+	}
+	apiPath, err := s.buildRecordPath("Records.Create", dbName, tableName)
 	if err != nil {
 		return 0, err
 	}
 
 	var result CreateRecordResponse
-	err = s.client.doRequest(ctx, http.MethodPost, apiPath, recordData, &result)
+	err = s.client.doRequest(ctx, "Records.Create", http.MethodPost, apiPath, recordData, &result)
 	if err != nil {
 		// Handles 400 (bad type/col), 401, 404 (db/table not found), 409 (constraint), 500
 		return 0, err
@@ -61,56 +55,111 @@ func (s *RecordService) Create(ctx context.Context, dbName, tableName string, re
 }
 
 // Get retrieves a single record by its ID.
-// Returns a map representing the record, or ErrNotFound if the record ID doesn't exist.
-func (s *RecordService) Get(ctx context.Context, dbName, tableName string, recordID int64) (map[string]interface{}, error) {
-	apiPath, err := s.buildSingleRecordPath(dbName, tableName, recordID)
+// Returns the record, or ErrRecordNotFound (which also matches ErrNotFound) if the record ID doesn't exist.
+// Use GetWithVersion to also get the record's version token for IfMatch.
+func (s *RecordService) Get(ctx context.Context, dbName, tableName string, recordID int64, reqOpts ...RequestOption) (Record, error) {
+	apiPath, err := s.buildSingleRecordPath("Records.Get", dbName, tableName, recordID)
 	if err != nil {
 		return nil, err // Handles invalid db/table/recordID
 	}
 
-	var result map[string]interface{} // Expecting a single JSON object
-	err = s.client.doRequest(ctx, http.MethodGet, apiPath, nil, &result)
+	var result Record // Expecting a single JSON object
+	err = s.client.doRequest(ctx, "Records.Get", http.MethodGet, apiPath, nil, &result, reqOpts...)
 	if err != nil {
 		// Handles 401, 404 (db/table/record not found), 500
 		return nil, err
 	}
+	if err := s.client.typeRecords(ctx, "Records.Get", dbName, tableName, result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // Update modifies fields of an existing record.
 // updateData should be a map containing *only* the fields to be changed.
 // Returns nil on success.
-func (s *RecordService) Update(ctx context.Context, dbName, tableName string, recordID int64, updateData map[string]interface{}) error {
+// Pass IfMatch(version) to make the update conditional on the record's version.
+func (s *RecordService) Update(ctx context.Context, dbName, tableName string, recordID int64, updateData map[string]interface{}, reqOpts ...RequestOption) error {
 	if len(updateData) == 0 {
 		return errors.New("update data cannot be empty")
 	}
-	apiPath, err := s.buildSingleRecordPath(dbName, tableName, recordID)
+	if err := validateRecordData(updateData).err("Records.Update"); err != nil {
+		return err
+	}
+	apiPath, err := s.buildSingleRecordPath("Records.Update", dbName, tableName, recordID)
 	if err != nil {
 		return err
 	}
 
 	// Backend returns 200 OK with body, but we only need to check for errors here.
 	// Pass nil for responseBody as we are just returning error.
-	err = s.client.doRequest(ctx, http.MethodPut, apiPath, updateData, nil)
+	err = s.client.doRequest(ctx, "Records.Update", http.MethodPut, apiPath, updateData, nil, reqOpts...)
 	if err != nil {
-		// Handles 400 (bad type/col), 401, 404 (db/table/record not found), 409 (constraint), 500
+		// Handles 400 (bad type/col), 401, 404 (db/table/record not found), 409 (constraint), 412 (version), 500
 		return err
 	}
 	return nil // Success (200 OK)
 }
 
+// UpdateWithResult modifies fields of an existing record like Update, but returns the
+// server's result: the number of rows affected and, if opts.ReturnRecord is set, the full
+// record after the update (fetched with a follow-up Get only if the server doesn't include it).
+func (s *RecordService) UpdateWithResult(ctx context.Context, dbName, tableName string, recordID int64, updateData map[string]interface{}, opts *UpdateOptions, reqOpts ...RequestOption) (*UpdateResult, error) {
+	if len(updateData) == 0 {
+		return nil, errors.New("update data cannot be empty")
+	}
+	if err := validateRecordData(updateData).err("Records.UpdateWithResult"); err != nil {
+		return nil, err
+	}
+	apiPath, err := s.buildSingleRecordPath("Records.UpdateWithResult", dbName, tableName, recordID)
+	if err != nil {
+		return nil, err
+	}
+	returnRecord := opts != nil && opts.ReturnRecord
+	if returnRecord {
+		apiPath += "?return=record" // Ask the server to include the updated record in the response
+	}
+
+	var result UpdateRecordResponse
+	err = s.client.doRequest(ctx, "Records.UpdateWithResult", http.MethodPut, apiPath, updateData, &result, reqOpts...)
+	if err != nil {
+		// Handles 400 (bad type/col), 401, 404 (db/table/record not found), 409 (constraint), 412 (version), 500
+		return nil, err
+	}
+
+	if result.Record != nil {
+		if err := s.client.typeRecords(ctx, "Records.UpdateWithResult", dbName, tableName, result.Record); err != nil {
+			return nil, err
+		}
+	}
+	updated := &UpdateResult{
+		RecordID:     recordID,
+		RowsAffected: result.RowsAffected,
+		Record:       result.Record,
+	}
+	if returnRecord && updated.Record == nil && updated.RowsAffected > 0 {
+		// Older servers ignore return=record; fall back to fetching it
+		updated.Record, err = s.Get(ctx, dbName, tableName, recordID)
+		if err != nil {
+			return updated, fmt.Errorf("record updated but fetching it failed: %w", err)
+		}
+	}
+	return updated, nil
+}
+
 // Delete removes a specific record by its ID.
 // Returns nil on success (204 No Content).
-func (s *RecordService) Delete(ctx context.Context, dbName, tableName string, recordID int64) error {
-	apiPath, err := s.buildSingleRecordPath(dbName, tableName, recordID)
+// Pass IfMatch(version) to make the delete conditional on the record's version.
+func (s *RecordService) Delete(ctx context.Context, dbName, tableName string, recordID int64, reqOpts ...RequestOption) error {
+	apiPath, err := s.buildSingleRecordPath("Records.Delete", dbName, tableName, recordID)
 	if err != nil {
 		return err
 	}
 
 	// Expect 204 No Content on success, responseBody is nil
-	err = s.client.doRequest(ctx, http.MethodDelete, apiPath, nil, nil)
+	err = s.client.doRequest(ctx, "Records.Delete", http.MethodDelete, apiPath, nil, nil, reqOpts...)
 	if err != nil {
-		// Handles 401, 404 (db/table/record not found), 500
+		// Handles 401, 404 (db/table/record not found), 412 (version), 500
 		return err
 	}
 	return nil // Success
@@ -118,26 +167,119 @@ func (s *RecordService) Delete(ctx context.Context, dbName, tableName string, re
 
 // List retrieves records from the specified table, optionally applying filters.
 // Filters are key-value pairs for simple equality matching (e.g., {"status":"active", "priority":"1"}).
-// Returns a slice of maps, where each map represents a record.
+// Returns a slice of Records.
 
 // --- *** MODIFIED: List retrieves records using ListRecordsOptions *** ---
 // Accepts optional parameters via the opts struct.
-func (s *RecordService) List(ctx context.Context, dbName, tableName string, opts *ListRecordsOptions) ([]map[string]interface{}, error) {
-	apiPath, err := s.buildRecordPath(dbName, tableName)
+// Records are returned as []Record. This is a breaking change from the earlier
+// []map[string]interface{} (see CHANGELOG.md): convert elements with map[string]interface{}(rec).
+// The whole response is held in memory; use Stream for tables larger than the response size limit.
+func (s *RecordService) List(ctx context.Context, dbName, tableName string, opts *ListRecordsOptions, reqOpts ...RequestOption) ([]Record, error) {
+	apiPath, err := s.buildListPath("Records.List", dbName, tableName, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	var result []Record // Expecting a JSON array of objects
+	err = s.client.doRequest(ctx, "Records.List", http.MethodGet, apiPath, nil, &result, reqOpts...)
+	if err != nil {
+		// Handles 400 (if backend adds validation for limit/offset/sort/filter), 401, 404, 500
+		return nil, err
+	}
+
+	if result == nil {
+		return make([]Record, 0), nil
+	}
+	if err := s.client.typeRecords(ctx, "Records.List", dbName, tableName, result...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// --- *** END MODIFIED *** ---
+
+// Stream retrieves records like List, but decodes the response array element by element
+// and calls fn for each record as it arrives, so memory use stays constant regardless of
+// table size. Returning an error from fn stops the stream and Stream returns that error.
+// No response size limit applies unless one is set for the call with MaxResponseBytes,
+// and the client's request timeout doesn't cut the stream off: bound it with ctx instead.
+func (s *RecordService) Stream(ctx context.Context, dbName, tableName string, opts *ListRecordsOptions, fn func(record Record) error, reqOpts ...RequestOption) error {
+	if fn == nil {
+		return errors.New("stream callback cannot be nil")
+	}
+	apiPath, err := s.buildListPath("Records.Stream", dbName, tableName, opts)
+	if err != nil {
+		return err
+	}
+
+	schema, err := s.client.recordSchema(ctx, "Records.Stream", dbName, tableName) // Before the stream holds a request slot
+	if err != nil {
+		return err
+	}
+
+	// Streaming is unlimited in size and duration by default; an explicit per-call limit still wins
+	ro := s.client.requestOptions(append([]RequestOption{longLivedRequest(), MaxResponseBytes(0)}, reqOpts...))
+	resp, err := s.client.send(ctx, "Records.Stream", http.MethodGet, apiPath, nil, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(limitBody(resp.Body, ro.maxResponseBytes))
+	tok, err := dec.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+	if tok == nil {
+		return nil // `null` body: no records
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("%w: expected JSON array of records, got %v", ErrInvalidResponse, tok)
+	}
+
+	for dec.More() {
+		var record Record
+		if err := dec.Decode(&record); err != nil {
+			return decodeError(err)
+		}
+		if err := applySchema("Records.Stream", schema, record); err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil { // Closing ']'
+		return decodeError(err)
+	}
+	return nil
+}
+
+// buildListPath constructs the records path with the query string for opts
+// (filters, pagination and sorting), validating filter keys for op.
+func (s *RecordService) buildListPath(op, dbName, tableName string, opts *ListRecordsOptions) (string, error) {
+	apiPath, err := s.buildRecordPath(op, dbName, tableName)
+	if err != nil {
+		return "", err
+	}
+
 	queryValues := url.Values{}
 
 	// Process options if provided
 	if opts != nil {
 		// Add Filters
-		if opts.Filters != nil {
-			for key, value := range opts.Filters {
-				if key != "" { // Ignore empty keys
-					queryValues.Add(key, value) // Value is already string
-				}
+		if err := addFilterQuery(op, queryValues, opts.Filters); err != nil {
+			return "", err
+		}
+
+		if opts.Limit != nil || opts.Offset != nil {
+			if err := s.client.requireCapability(op, CapabilityPagination); err != nil {
+				return "", err
+			}
+		}
+		if opts.SortBy != nil && *opts.SortBy != "" {
+			if err := s.client.requireCapability(op, CapabilitySorting); err != nil {
+				return "", err
 			}
 		}
 
@@ -176,18 +318,18 @@ func (s *RecordService) List(ctx context.Context, dbName, tableName string, opts
 	if len(queryValues) > 0 {
 		apiPath = fmt.Sprintf("%s?%s", apiPath, queryValues.Encode())
 	}
+	return apiPath, nil
+}
 
-	var result []map[string]interface{} // Expecting a JSON array of objects
-	err = s.client.doRequest(ctx, http.MethodGet, apiPath, nil, &result)
-	if err != nil {
-		// Handles 400 (if backend adds validation for limit/offset/sort/filter), 401, 404, 500
-		return nil, err
+// addFilterQuery validates filter for op and adds its entries to queryValues.
+func addFilterQuery(op string, queryValues url.Values, filter Filter) error {
+	if err := validateFilters(filter).err(op); err != nil {
+		return err
 	}
-
-	if result == nil {
-		return make([]map[string]interface{}, 0), nil
+	for key, value := range filter {
+		if key != "" { // Ignore empty keys
+			queryValues.Add(key, value) // Value is already string
+		}
 	}
-	return result, nil
+	return nil
 }
-
-// --- *** END MODIFIED *** ---
@@ -0,0 +1,230 @@
+// recordvalue.go
+package nebula
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Record is a single table row as returned by RecordService.Get, List and Stream.
+//
+// With WithTypedRecords, values follow the column schema: INTEGER holds int64, REAL
+// float64, BOOLEAN bool, BLOB []byte, TEXT string and NULL nil. Otherwise numbers are
+// decoded by their JSON form: integral numbers hold int64 (no float64 rounding above
+// 2^53) and all others float64, so a REAL column holding 3.0 yields int64(3), and BLOBs
+// hold their base64 string. The typed accessors (Int64, Float, Bool, Bytes, ...) convert
+// on read either way, and return a *ColumnTypeError on mismatch.
+type Record map[string]interface{}
+
+// ColumnTypeError is returned by Record accessors when a column is missing or its
+// value can't be converted to the requested type. It matches ErrColumnType via errors.Is,
+// and ErrColumnNotFound as well when the column is missing.
+type ColumnTypeError struct {
+	Column string      // Column name that was accessed
+	Want   string      // Requested type (e.g., "int64")
+	Value  interface{} // Actual value (nil for NULL or missing)
+	Err    error       // Optional cause (e.g., ErrColumnNotFound, a base64 or time parse error)
+}
+
+// Error implements the error interface for ColumnTypeError.
+func (e *ColumnTypeError) Error() string {
+	if errors.Is(e.Err, ErrColumnNotFound) {
+		return fmt.Sprintf("column %q: %v", e.Column, e.Err)
+	}
+	msg := fmt.Sprintf("column %q: cannot convert %T (%v) to %s", e.Column, e.Value, e.Value, e.Want)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap allows errors.Is(err, ErrColumnType) and errors.Is(err, ErrColumnNotFound).
+func (e *ColumnTypeError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrColumnType, e.Err}
+	}
+	return []error{ErrColumnType}
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding numbers without loss of precision:
+// integral numbers become int64 and all others float64.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*r = nil
+		return nil
+	}
+	for k, v := range raw {
+		raw[k] = normalizeValue(v)
+	}
+	*r = raw
+	return nil
+}
+
+// normalizeValue converts json.Number values produced by UseNumber into int64 or float64.
+func normalizeValue(v interface{}) interface{} {
+	num, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := num.Int64(); err == nil {
+		return i
+	}
+	if f, err := num.Float64(); err == nil {
+		return f
+	}
+	return num.String() // Out of range for both; keep the literal rather than lose it
+}
+
+// IsNull reports whether the column is present and NULL.
+func (r Record) IsNull(column string) bool {
+	v, ok := r[column]
+	return ok && v == nil
+}
+
+// lookup returns the value of column or a ColumnTypeError if it is missing.
+func (r Record) lookup(column, want string) (interface{}, error) {
+	v, ok := r[column]
+	if !ok {
+		return nil, &ColumnTypeError{Column: column, Want: want, Err: ErrColumnNotFound}
+	}
+	return v, nil
+}
+
+// Int64 returns the value of an INTEGER column.
+// Float values are accepted if they are integral and within int64 range.
+func (r Record) Int64(column string) (int64, error) {
+	v, err := r.lookup(column, "int64")
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), nil
+		}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	return 0, &ColumnTypeError{Column: column, Want: "int64", Value: v}
+}
+
+// Float returns the value of a REAL (or INTEGER) column as float64.
+func (r Record) Float(column string) (float64, error) {
+	v, err := r.lookup(column, "float64")
+	if err != nil {
+		return 0, err
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return 0, &ColumnTypeError{Column: column, Want: "float64", Value: v}
+}
+
+// String returns the value of a TEXT column.
+func (r Record) String(column string) (string, error) {
+	v, err := r.lookup(column, "string")
+	if err != nil {
+		return "", err
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", &ColumnTypeError{Column: column, Want: "string", Value: v}
+}
+
+// Bool returns the value of a BOOLEAN column.
+// Integer 0 and 1 are accepted since the backend may store booleans as integers.
+func (r Record) Bool(column string) (bool, error) {
+	v, err := r.lookup(column, "bool")
+	if err != nil {
+		return false, err
+	}
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case int64:
+		if b == 0 || b == 1 {
+			return b == 1, nil
+		}
+	}
+	return false, &ColumnTypeError{Column: column, Want: "bool", Value: v}
+}
+
+// Bytes returns the value of a BLOB column, decoding it from base64.
+func (r Record) Bytes(column string) ([]byte, error) {
+	v, err := r.lookup(column, "[]byte")
+	if err != nil {
+		return nil, err
+	}
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case string:
+		decoded, err := base64.StdEncoding.DecodeString(b)
+		if err != nil {
+			return nil, &ColumnTypeError{Column: column, Want: "[]byte", Value: v, Err: err}
+		}
+		return decoded, nil
+	}
+	return nil, &ColumnTypeError{Column: column, Want: "[]byte", Value: v}
+}
+
+// timeLayouts lists the timestamp formats accepted by Record.Time, in order.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999", // SQLite CURRENT_TIMESTAMP / datetime()
+	"2006-01-02",
+}
+
+// Time returns the value of a timestamp column. RFC 3339 strings, SQLite datetime strings
+// (interpreted as UTC) and integer Unix seconds are accepted.
+func (r Record) Time(column string) (time.Time, error) {
+	v, err := r.lookup(column, "time.Time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case int64:
+		return time.Unix(t, 0).UTC(), nil
+	case string:
+		var lastErr error
+		for _, layout := range timeLayouts {
+			parsed, err := time.Parse(layout, t)
+			if err == nil {
+				return parsed, nil
+			}
+			lastErr = err
+		}
+		return time.Time{}, &ColumnTypeError{Column: column, Want: "time.Time", Value: v, Err: lastErr}
+	}
+	return time.Time{}, &ColumnTypeError{Column: column, Want: "time.Time", Value: v}
+}
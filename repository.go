@@ -0,0 +1,384 @@
+// repository.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BeforeSaver is implemented by entities that need to run logic (validation, timestamps)
+// before Repository.Save writes them. Returning an error aborts the save.
+type BeforeSaver interface {
+	BeforeSave(ctx context.Context) error
+}
+
+// AfterLoader is implemented by entities that need to run logic (derived fields) after
+// a Repository has loaded them. Returning an error fails the load.
+type AfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// Repository provides typed access to one table, mapping records to values of the struct
+// type T. Columns map to exported fields through `nebula:"column"` tags; untagged fields
+// use their name in snake_case (CreatedAt → created_at), and `nebula:"-"` skips a field.
+// T must have an integer field mapped to the "id" column.
+//
+// Supported field types are integers, floats, strings, bools, []byte (BLOB), time.Time,
+// and pointers to these (nil for NULL). Hooks are called on *T if it implements
+// BeforeSaver or AfterLoader.
+//
+//	type Widget struct {
+//		ID        int64     `nebula:"id"`
+//		Name      string    `nebula:"name"`
+//		Price     *float64  `nebula:"price"`
+//		UpdatedAt time.Time `nebula:"updated_at"`
+//	}
+//
+//	widgets, err := nebula.NewRepository[Widget](client, "inventory", "widgets")
+//	w, err := widgets.FindByID(ctx, 42)
+type Repository[T any] struct {
+	client    *Client
+	dbName    string
+	tableName string
+	mapping   *structMapping
+}
+
+// NewRepository creates a Repository for dbName.tableName. It fails if T is not a struct
+// or its fields can't be mapped.
+func NewRepository[T any](client *Client, dbName, tableName string) (*Repository[T], error) {
+	if client == nil {
+		return nil, errors.New("repository client cannot be nil")
+	}
+	mapping, err := newStructMapping(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &Repository[T]{client: client, dbName: dbName, tableName: tableName, mapping: mapping}, nil
+}
+
+// FindByID returns the entity with the given ID, or ErrRecordNotFound.
+func (r *Repository[T]) FindByID(ctx context.Context, id int64) (*T, error) {
+	record, err := r.client.Records.Get(ctx, r.dbName, r.tableName, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.load(ctx, record)
+}
+
+// FindAll returns the entities matching query (filters, sorting and pagination as for
+// RecordService.List). A nil query returns every entity.
+func (r *Repository[T]) FindAll(ctx context.Context, query *ListRecordsOptions) ([]T, error) {
+	records, err := r.client.Records.List(ctx, r.dbName, r.tableName, query)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]T, 0, len(records))
+	for _, record := range records {
+		entity, err := r.load(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, *entity)
+	}
+	return entities, nil
+}
+
+// FindOne returns the first entity (in ID order) matching filter, or ErrRecordNotFound.
+func (r *Repository[T]) FindOne(ctx context.Context, filter Filter) (*T, error) {
+	limit, sortBy, sortDir := 1, r.mapping.id.column, "asc"
+	records, err := r.client.Records.List(ctx, r.dbName, r.tableName, &ListRecordsOptions{
+		Filters:       filter,
+		Limit:         &limit,
+		SortBy:        &sortBy,
+		SortDirection: &sortDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no %s record matches the filter: %w", r.tableName, ErrRecordNotFound)
+	}
+	return r.load(ctx, records[0])
+}
+
+// Save inserts entity if its ID is zero, setting the ID to the one assigned by the
+// server; otherwise it updates every mapped column of the existing record.
+// BeforeSave is called first if *T implements BeforeSaver.
+func (r *Repository[T]) Save(ctx context.Context, entity *T) error {
+	if entity == nil {
+		return errors.New("repository entity cannot be nil")
+	}
+	if hook, ok := any(entity).(BeforeSaver); ok {
+		if err := hook.BeforeSave(ctx); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(entity).Elem()
+	id := v.FieldByIndex(r.mapping.id.index).Int()
+	data, err := r.mapping.encode(v)
+	if err != nil {
+		return err
+	}
+
+	if id == 0 {
+		newID, err := r.client.Records.Create(ctx, r.dbName, r.tableName, data)
+		if err != nil {
+			return err
+		}
+		v.FieldByIndex(r.mapping.id.index).SetInt(newID)
+		return nil
+	}
+	return r.client.Records.Update(ctx, r.dbName, r.tableName, id, data)
+}
+
+// Delete removes the entity with the given ID. Returns ErrRecordNotFound if there is none.
+func (r *Repository[T]) Delete(ctx context.Context, id int64) error {
+	return r.client.Records.Delete(ctx, r.dbName, r.tableName, id)
+}
+
+// Count returns the number of entities matching filter (all entities if filter is empty).
+func (r *Repository[T]) Count(ctx context.Context, filter Filter) (int64, error) {
+	return r.client.Records.Count(ctx, r.dbName, r.tableName, filter)
+}
+
+// Exists reports whether an entity with the given ID exists.
+func (r *Repository[T]) Exists(ctx context.Context, id int64) (bool, error) {
+	_, err := r.client.Records.Get(ctx, r.dbName, r.tableName, id)
+	if errors.Is(err, ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// load decodes record into a new T and runs its AfterLoad hook.
+func (r *Repository[T]) load(ctx context.Context, record Record) (*T, error) {
+	entity := new(T)
+	if err := r.mapping.decode(record, reflect.ValueOf(entity).Elem()); err != nil {
+		return nil, fmt.Errorf("decoding %s record %v: %w", r.tableName, record["id"], err)
+	}
+	if hook, ok := any(entity).(AfterLoader); ok {
+		if err := hook.AfterLoad(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return entity, nil
+}
+
+// fieldMapping maps one struct field to a column.
+type fieldMapping struct {
+	column string
+	index  []int // Field index path, for promoted fields of embedded structs
+	name   string
+}
+
+// structMapping maps the fields of a struct type to columns.
+type structMapping struct {
+	fields []fieldMapping // Every mapped field except the ID
+	id     fieldMapping
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// newStructMapping builds the column mapping for struct type t.
+func newStructMapping(t reflect.Type) (*structMapping, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("repository type %s must be a struct", t)
+	}
+	m := &structMapping{}
+	seen := make(map[string]string)
+	hasID := false
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != timeType) {
+			continue // Unexported, or an embedded struct whose fields are visited individually
+		}
+		column := f.Tag.Get("nebula")
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			column = toSnakeCase(f.Name)
+		}
+		if other, ok := seen[column]; ok {
+			return nil, fmt.Errorf("repository type %s: fields %s and %s both map to column %q", t, other, f.Name, column)
+		}
+		seen[column] = f.Name
+		if !supportedFieldType(f.Type) {
+			return nil, fmt.Errorf("repository type %s: field %s has unsupported type %s", t, f.Name, f.Type)
+		}
+
+		fm := fieldMapping{column: column, index: f.Index, name: f.Name}
+		if column == "id" {
+			switch f.Type.Kind() {
+			case reflect.Int, reflect.Int64:
+			default:
+				return nil, fmt.Errorf("repository type %s: ID field %s must be int or int64, not %s", t, f.Name, f.Type)
+			}
+			m.id, hasID = fm, true
+			continue
+		}
+		m.fields = append(m.fields, fm)
+	}
+	if !hasID {
+		return nil, fmt.Errorf("repository type %s has no field mapped to the \"id\" column", t)
+	}
+	return m, nil
+}
+
+// supportedFieldType reports whether values of t can be converted to and from columns.
+func supportedFieldType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// encode converts the mapped fields of v (except the ID) to record data.
+func (m *structMapping) encode(v reflect.Value) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(m.fields))
+	for _, f := range m.fields {
+		fv := v.FieldByIndex(f.index)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				data[f.column] = nil
+				continue
+			}
+			fv = fv.Elem()
+		}
+		switch {
+		case fv.Type() == timeType:
+			data[f.column] = fv.Interface().(time.Time).UTC().Format(time.RFC3339Nano)
+		case fv.Kind() == reflect.Slice:
+			data[f.column] = fv.Bytes() // Sent base64-encoded, as BLOB columns expect
+		case fv.CanInt():
+			data[f.column] = fv.Int()
+		case fv.CanUint():
+			if fv.Uint() > math.MaxInt64 {
+				return nil, fmt.Errorf("field %s: value %d overflows INTEGER", f.name, fv.Uint())
+			}
+			data[f.column] = int64(fv.Uint())
+		case fv.CanFloat():
+			data[f.column] = fv.Float()
+		case fv.Kind() == reflect.String:
+			data[f.column] = fv.String()
+		case fv.Kind() == reflect.Bool:
+			data[f.column] = fv.Bool()
+		}
+	}
+	return data, nil
+}
+
+// decode sets the mapped fields of v from record. Columns missing from the record leave
+// fields at their zero value; NULL sets pointers to nil and other fields to zero.
+func (m *structMapping) decode(record Record, v reflect.Value) error {
+	for _, f := range append([]fieldMapping{m.id}, m.fields...) {
+		raw, ok := record[f.column]
+		if !ok {
+			continue
+		}
+		fv := v.FieldByIndex(f.index)
+		if raw == nil {
+			fv.SetZero()
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+		if err := decodeColumn(record, f.column, fv); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// decodeColumn converts the record's column value with the typed accessors and stores it in fv.
+func decodeColumn(record Record, column string, fv reflect.Value) error {
+	switch {
+	case fv.Type() == timeType:
+		t, err := record.Time(column)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+	case fv.Kind() == reflect.Slice:
+		b, err := record.Bytes(column)
+		if err != nil {
+			return err
+		}
+		fv.SetBytes(b)
+	case fv.CanInt():
+		i, err := record.Int64(column)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, fv.Type())
+		}
+		fv.SetInt(i)
+	case fv.CanUint():
+		i, err := record.Int64(column)
+		if err != nil {
+			return err
+		}
+		if i < 0 || fv.OverflowUint(uint64(i)) {
+			return fmt.Errorf("value %d overflows %s", i, fv.Type())
+		}
+		fv.SetUint(uint64(i))
+	case fv.CanFloat():
+		f, err := record.Float(column)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case fv.Kind() == reflect.String:
+		s, err := record.String(column)
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	case fv.Kind() == reflect.Bool:
+		b, err := record.Bool(column)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	}
+	return nil
+}
+
+// toSnakeCase converts a Go field name to snake_case, keeping initialisms together
+// (UserID → user_id, HTTPStatus → http_status).
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -5,32 +5,73 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 )
 
-const maxResponseBody = 1 * 1024 * 1024 // Limit response body read size to 1MB for safety
+const (
+	maxErrorResponseBody    = 1 * 1024 * 1024  // Limit error response body read size to 1MB for safety
+	defaultMaxResponseBytes = 32 * 1024 * 1024 // Default limit for successful response bodies (see WithMaxResponseBytes)
+)
 
 // doRequest performs an HTTP request to the Nebula API.
 // It handles context, method, path joining, request body marshaling, auth header,
 // response status checking, error mapping, and response body unmarshaling.
 // - ctx: Context for cancellation/timeout.
 // - c: The client instance containing config and auth token.
+// - op: The SDK operation name (e.g., "Records.Get"), recorded on returned APIErrors.
 // - method: HTTP method (e.g., http.MethodGet).
 // - apiPath: The API endpoint path *without* the base URL or leading slash (e.g., "auth/login", "databases").
 // - requestBody: The struct/map to be marshaled into JSON for the request body (or nil).
 // - responseBody: A pointer to a struct/map where the JSON response body should be unmarshaled (or nil).
-func (c *Client) doRequest(ctx context.Context, method, apiPath string, requestBody interface{}, responseBody interface{}) error {
-	// 1. Construct full URL
-	// Use JoinPath available in Go 1.19+ for cleaner path handling
-	// For older Go or simplicity:
-	fullURL := c.baseURL.String() + strings.TrimPrefix(apiPath, "/")
-	// Or using JoinPath:
-	// relativeURL, err := url.Parse(apiPath) // Check error
-	// fullURL := c.baseURL.ResolveReference(relativeURL).String()
+// - opts: Per-call options (e.g., MaxResponseBytes) overriding client defaults.
+func (c *Client) doRequest(ctx context.Context, op, method, apiPath string, requestBody interface{}, responseBody interface{}, opts ...RequestOption) error {
+	ro := c.requestOptions(opts)
+	if c.concurrency != nil {
+		if err := c.concurrency.acquire(ctx); err != nil {
+			return err
+		}
+		defer c.concurrency.release()
+	}
+	if method != http.MethodGet {
+		if !ro.readOnly {
+			defer c.invalidateCache(apiPath)
+			defer c.forgetSchemas(apiPath)
+		}
+	} else if c.cache != nil && responseBody != nil && !ro.longLived {
+		return c.doCachedRequest(ctx, op, apiPath, responseBody, ro)
+	}
+
+	resp, err := c.send(ctx, op, method, apiPath, requestBody, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if ro.responseHeader != nil {
+		*ro.responseHeader = resp.Header
+	}
+
+	// 7. Process successful response body (if expected), decoding as it streams in
+	if responseBody != nil && resp.StatusCode != http.StatusNoContent {
+		dec := json.NewDecoder(limitBody(resp.Body, ro.maxResponseBytes))
+		if err := dec.Decode(responseBody); err != nil {
+			return decodeError(err)
+		}
+	}
+
+	return nil // Success
+}
+
+// send performs steps 1-6 of a request: building and executing it and mapping
+// error responses. On success the caller owns the returned response and must close its body.
+func (c *Client) send(ctx context.Context, op, method, apiPath string, requestBody interface{}, ro requestOptions) (*http.Response, error) {
+	// 1. Construct full URL (apiPath segments are already escaped, see apiPath)
+	fullURL := c.resolveURL(apiPath)
 
 	// 2. Prepare request body (if any)
 	var bodyReader io.Reader
@@ -39,7 +80,7 @@ func (c *Client) doRequest(ctx context.Context, method, apiPath string, requestB
 	if requestBody != nil {
 		reqBytes, err = json.Marshal(requestBody)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(reqBytes)
 	}
@@ -47,7 +88,7 @@ func (c *Client) doRequest(ctx context.Context, method, apiPath string, requestB
 	// 3. Create request with context
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// 4. Set headers
@@ -55,14 +96,18 @@ func (c *Client) doRequest(ctx context.Context, method, apiPath string, requestB
 	if requestBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for key, values := range ro.header {
+		req.Header[key] = values // Per-call headers (e.g., If-Match)
+	}
 
 	// Check if path requires authentication (simple prefix check for now)
 	// Note: Assumes all paths under apiVersionPath require auth except /auth/*
-	if strings.HasPrefix(apiPath, strings.TrimPrefix(apiVersionPath, "/")) &&
-		!strings.HasPrefix(apiPath, "auth/") {
+	relPath := strings.TrimPrefix(apiPath, "/")
+	if strings.HasPrefix(relPath, strings.TrimPrefix(apiVersionPath, "/")) &&
+		!strings.HasPrefix(relPath, "auth/") {
 		if c.authToken == "" {
 			log.Println("SDK Error: Attempted protected API call without auth token set.")
-			return ErrAuthTokenMissing // Use SDK specific error
+			return nil, ErrAuthTokenMissing // Use SDK specific error
 		}
 		req.Header.Set("Authorization", "Bearer "+c.authToken)
 	}
@@ -71,63 +116,227 @@ func (c *Client) doRequest(ctx context.Context, method, apiPath string, requestB
 	// log.Printf("SDK Request: %s %s", method, fullURL)
 	// if requestBody != nil { log.Printf("SDK Request Body: %s", string(reqBytes)) }
 
-	// 5. Execute request
-	resp, err := c.httpClient.Do(req)
+	// 5. Execute request, once the client-side rate limit allows it
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	httpClient := c.httpClient
+	if ro.longLived && httpClient.Timeout > 0 {
+		streamClient := *httpClient // Shares the Transport; only the overall timeout differs
+		streamClient.Timeout = 0
+		httpClient = &streamClient
+	}
+	var probe bool
+	if c.breaker != nil {
+		if probe, err = c.breaker.allow(); err != nil {
+			return nil, err // Fail fast while Nebula is down
+		}
+	}
+	resp, err := c.do(httpClient, req, apiPath)
 	if err != nil {
-		// Wrap network/transport errors
-		return fmt.Errorf("http request failed: %w", err)
+		if ctx.Err() != nil {
+			// Caller cancelled or its deadline passed; not a transport failure
+			err = fmt.Errorf("http request failed: %w", ctx.Err())
+		} else {
+			// Wrap network/transport errors so IsTemporary/IsRetryable can classify them
+			err = &transportError{method: method, conditional: req.Header.Get("If-Match") != "", err: err}
+		}
+		if c.breaker != nil {
+			c.breaker.record(probe, 0, err)
+		}
+		return nil, err
+	}
+	if c.breaker != nil {
+		c.breaker.record(probe, resp.StatusCode, nil)
 	}
-	defer resp.Body.Close()
 
 	// Log response status (optional)
 	// log.Printf("SDK Response Status: %s", resp.Status)
 
 	// 6. Check status code for errors (>= 400)
+	if c.limiter != nil {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.limiter.onThrottled(parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		} else if resp.StatusCode < 400 {
+			c.limiter.onSuccess()
+		}
+	}
 	if resp.StatusCode >= 400 {
-		// Limit reading response body to prevent resource exhaustion
-		limitedReader := io.LimitReader(resp.Body, maxResponseBody)
-		respBytes, readErr := io.ReadAll(limitedReader)
-		if readErr != nil {
-			log.Printf("SDK Error: Failed to read error response body: %v", readErr)
-			// Return an error based on status code but mention body read failure
-			return mapHTTPError(resp.StatusCode, "failed to read error body", readErr)
-		}
-
-		// Try to unmarshal standard API error response `{"error": "message"}`
-		var apiErrResp ErrorResponse
-		jsonErr := json.Unmarshal(respBytes, &apiErrResp)
-		errMsg := ""
-		if jsonErr == nil && apiErrResp.Error != "" {
-			errMsg = apiErrResp.Error // Use message from API if successfully parsed
-		} else {
-			// Fallback if body wasn't JSON or didn't match expected structure
-			errMsg = fmt.Sprintf("API returned status %d", resp.StatusCode)
-			if len(respBytes) > 0 && len(respBytes) < 200 { // Log small unknown bodies
-				errMsg += " (" + string(respBytes) + ")"
-			}
-			log.Printf("SDK Warning: Could not parse API error response body (Status %d): %v. Body: %s", resp.StatusCode, jsonErr, string(respBytes))
+		defer resp.Body.Close()
+		apiErr := c.readAPIError(resp, method, apiPath)
+		apiErr.Operation = op
+		apiErr.Method = method
+		apiErr.Path = apiPath
+		apiErr.RequestID = resp.Header.Get("X-Request-ID")
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// decodeError converts an error from decoding a success response body into an SDK error.
+func decodeError(err error) error {
+	if errors.Is(err, ErrResponseTooLarge) {
+		return err
+	}
+	log.Printf("SDK Error: Failed to decode success response body: %v", err)
+	// Return specific error indicating response parsing failure
+	return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+}
+
+// limitBody wraps a response body so that reading more than limit bytes fails with
+// ErrResponseTooLarge instead of silently truncating. A limit <= 0 means no limit.
+func limitBody(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &limitedBody{r: r, remaining: limit, limit: limit}
+}
+
+// limitedBody is the io.Reader returned by limitBody.
+type limitedBody struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+// Read implements io.Reader.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for one more byte to distinguish "exactly at limit" from "over limit"
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w (limit %d bytes)", ErrResponseTooLarge, l.limit)
 		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// readAPIError reads an error response body and maps it to an *APIError.
+// The endpoint context (method and path) is used to pick resource-specific SDK errors.
+func (c *Client) readAPIError(resp *http.Response, method, apiPath string) *APIError {
+	kind := resourceFromPath(apiPath)
 
-		// Use the helper function to map HTTP status to SDK error variable/type
-		return mapHTTPError(resp.StatusCode, errMsg, nil) // Pass nil as underlying error unless there was a readErr?
+	// Limit reading response body to prevent resource exhaustion
+	limitedReader := io.LimitReader(resp.Body, maxErrorResponseBody)
+	respBytes, readErr := io.ReadAll(limitedReader)
+	if readErr != nil {
+		log.Printf("SDK Error: Failed to read error response body: %v", readErr)
+		// Return an error based on status code but mention body read failure
+		return mapHTTPError(resp.StatusCode, kind, method, "", "failed to read error body", readErr)
 	}
 
-	// 7. Process successful response body (if expected)
-	if responseBody != nil && resp.StatusCode != http.StatusNoContent {
-		limitedReader := io.LimitReader(resp.Body, maxResponseBody)
-		respBytes, readErr := io.ReadAll(limitedReader)
-		if readErr != nil {
-			log.Printf("SDK Error: Failed to read success response body: %v", readErr)
-			return fmt.Errorf("%w: %w", ErrInvalidResponse, readErr)
+	// Try to unmarshal the API error response, simple (`{"error": "message"}`) or structured
+	var apiErrResp ErrorResponse
+	jsonErr := json.Unmarshal(respBytes, &apiErrResp)
+	errMsg := ""
+	if jsonErr == nil && (apiErrResp.Error != "" || apiErrResp.Code != "" || len(apiErrResp.Details) > 0) {
+		errMsg = apiErrResp.Error // Use message from API if successfully parsed
+	} else {
+		// Fallback if body wasn't JSON or didn't match expected structure
+		errMsg = fmt.Sprintf("API returned status %d", resp.StatusCode)
+		if len(respBytes) > 0 && len(respBytes) < 200 { // Log small unknown bodies
+			errMsg += " (" + string(respBytes) + ")"
 		}
+		log.Printf("SDK Warning: Could not parse API error response body (Status %d): %v. Body: %s", resp.StatusCode, jsonErr, string(respBytes))
+	}
 
-		err = json.Unmarshal(respBytes, responseBody)
-		if err != nil {
-			log.Printf("SDK Error: Failed to unmarshal success response body: %v. Body: %s", err, string(respBytes))
-			// Return specific error indicating response parsing failure
-			return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	// Use the helper function to map HTTP status and endpoint to SDK error variables
+	apiErr := mapHTTPError(resp.StatusCode, kind, method, apiErrResp.Code, errMsg, nil)
+	apiErr.Details = apiErrResp.Details
+	return apiErr
+}
+
+// resourceKind identifies which Nebula resource an API path addresses.
+// It lets generic HTTP errors (404, 409) be reported as resource-specific SDK errors.
+type resourceKind int
+
+const (
+	resourceUnknown   resourceKind = iota // Auth endpoints, sub-endpoints (count, transaction, ...) or unrecognised paths
+	resourceDatabases                     // The database collection: databases
+	resourceDatabase                      // A database: databases/{db}, .../schema, .../tables
+	resourceTable                         // A table: databases/{db}/tables/{table}, .../schema, .../records
+	resourceRecord                        // A record: databases/{db}/tables/{table}/records/{id}
+)
+
+// resourceFromPath determines the resourceKind addressed by an API path. Only the
+// exact shapes of known resources are recognised: a 404 from any other endpoint (e.g.,
+// records/count on a server without it) must not be reported as a missing database,
+// table or record.
+func resourceFromPath(apiPath string) resourceKind {
+	p := apiPath
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		p = p[:i] // Query string doesn't affect the addressed resource
+	}
+	p = strings.Trim(p, "/")
+	p = strings.TrimPrefix(p, strings.Trim(apiVersionPath, "/"))
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+
+	if len(segs) == 0 || segs[0] != "databases" {
+		return resourceUnknown
+	}
+	switch len(segs) {
+	case 1:
+		return resourceDatabases
+	case 2:
+		return resourceDatabase
+	case 3:
+		if segs[2] == "schema" || segs[2] == "tables" {
+			return resourceDatabase
+		}
+	case 4:
+		if segs[2] == "tables" {
+			return resourceTable
+		}
+	case 5:
+		if segs[2] == "tables" && (segs[4] == "schema" || segs[4] == "records") {
+			return resourceTable
+		}
+	case 6:
+		if segs[2] == "tables" && segs[4] == "records" && isRecordID(segs[5]) {
+			return resourceRecord
 		}
 	}
+	return resourceUnknown
+}
 
-	return nil // Success
+// isRecordID reports whether a path segment is a numeric record ID
+// (as opposed to a sub-resource of the records collection).
+func isRecordID(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	for _, r := range seg {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// endpointUnsupported reports whether err means the server doesn't implement the endpoint
+// (an older server), as opposed to, e.g., the database or table not existing.
+func endpointUnsupported(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		// Only an unrouted path; a server-reported missing db/table is a real error
+		return apiErr.Code == ""
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusNotAcceptable:
+		return true
+	}
+	return false
 }
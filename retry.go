@@ -0,0 +1,152 @@
+// retry.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// transportError marks an error returned by the underlying http.Client while the
+// caller's context was still live, so it can be classified independently of
+// context cancellation (which is never retryable).
+type transportError struct {
+	method      string // HTTP method of the failed request
+	conditional bool   // The request carried If-Match
+	err         error
+}
+
+func (e *transportError) Error() string { return "http request failed: " + e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+// temporaryStatus reports whether an HTTP status describes a transient condition
+// on the server or an intermediary that is expected to clear on its own.
+func temporaryStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, // 408
+		http.StatusTooManyRequests,    // 429
+		http.StatusBadGateway,         // 502
+		http.StatusServiceUnavailable, // 503
+		http.StatusGatewayTimeout:     // 504
+		return true
+	}
+	return false
+}
+
+// retryableStatus reports whether a request that failed with statusCode is worth
+// repeating unchanged, as classified by IsRetryable.
+func retryableStatus(statusCode int) bool {
+	return temporaryStatus(statusCode) || statusCode == http.StatusInternalServerError
+}
+
+// temporaryTransport reports whether a transport-level error is transient
+// (timeouts, refused or reset connections, connections closed mid-response).
+func temporaryTransport(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// IsTemporary reports whether err describes a transient condition that is expected
+// to clear on its own: transport timeouts and connection failures, or API responses
+// with status 408, 429, 502, 503 or 504, and ErrCircuitOpen. Context cancellation is
+// never temporary.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Transport errors are classified before context errors: an http.Client timeout
+	// may wrap context.DeadlineExceeded even though the caller's context is live.
+	var tErr *transportError
+	if errors.As(err, &tErr) {
+		return temporaryTransport(tErr.err)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return temporaryStatus(apiErr.StatusCode)
+	}
+	return temporaryTransport(err)
+}
+
+// IsRetryable reports whether the failed request is worth repeating unchanged.
+// It is true for every temporary error (see IsTemporary) and additionally for
+// 500 Internal Server Error responses. Client errors (4xx other than 408/429),
+// validation failures and context cancellation are not retryable.
+//
+// Transport errors of non-idempotent requests (POST, PATCH, and any request sent with
+// IfMatch) are only retryable if no connection was established, since the server may have applied a request whose
+// response was lost. Error responses say nothing about that: a 500, 502 or 504 to a
+// POST may follow a partial or complete write, so retry writes only if repeating them
+// is harmless.
+func IsRetryable(err error) bool {
+	var tErr *transportError
+	if errors.As(err, &tErr) && !failoverSafe(tErr.method, tErr.conditional, tErr.err) {
+		return false
+	}
+	if IsTemporary(err) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.StatusCode)
+	}
+	return false
+}
+
+// RetryAfter returns the delay the server asked the client to wait before
+// retrying, taken from the Retry-After header of a 429 or 503 response, or the time
+// until an open circuit breaker lets the next probe through (see ErrCircuitOpen).
+// The boolean is false if err carries no such hint.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	var openErr *circuitOpenError
+	if errors.As(err, &openErr) && openErr.retryIn > 0 {
+		return openErr.retryIn, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header value given either as
+// delay-seconds or as an HTTP date. It returns 0 if the value is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+// retry_test.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+)
+
+func TestIsRetryable(t *testing.T) {
+	reset := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	dial := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"GET reset", &transportError{method: http.MethodGet, err: reset}, true},
+		{"PUT reset", &transportError{method: http.MethodPut, err: reset}, true},
+		{"conditional PUT reset", &transportError{method: http.MethodPut, conditional: true, err: reset}, false},
+		{"conditional PUT dial", &transportError{method: http.MethodPut, conditional: true, err: dial}, true},
+		{"POST reset", &transportError{method: http.MethodPost, err: reset}, false},
+		{"PATCH reset", &transportError{method: http.MethodPatch, err: reset}, false},
+		{"POST dial", &transportError{method: http.MethodPost, err: dial}, true},
+		{"500", mapHTTPError(500, resourceUnknown, http.MethodPost, "", "", nil), true},
+		{"503", mapHTTPError(503, resourceUnknown, http.MethodGet, "", "", nil), true},
+		{"404", mapHTTPError(404, resourceRecord, http.MethodGet, "", "", nil), false},
+		{"circuit open", &circuitOpenError{}, true},
+		{"cancelled", fmt.Errorf("http request failed: %w", context.Canceled), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
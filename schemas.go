@@ -0,0 +1,131 @@
+// schemas.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ApplySchema converts r's values in place to the Go types of their declared column
+// types: INTEGER to int64, REAL to float64, BOOLEAN to bool and BLOB to []byte (decoded
+// from base64). NULLs, columns the schema doesn't declare and TEXT or other column types
+// are left as decoded. It returns the *ColumnTypeError of the first value that doesn't
+// convert, leaving that value unchanged.
+//
+// Clients created with WithTypedRecords apply the table schema automatically.
+func (r Record) ApplySchema(schema *SchemaPayload) error {
+	if schema == nil {
+		return nil
+	}
+	for _, col := range schema.Columns {
+		if v, ok := r[col.Name]; !ok || v == nil {
+			continue
+		}
+		var (
+			v   interface{}
+			err error
+		)
+		switch strings.ToUpper(col.Type) {
+		case "INTEGER":
+			v, err = r.Int64(col.Name)
+		case "REAL":
+			v, err = r.Float(col.Name)
+		case "BOOLEAN":
+			v, err = r.Bool(col.Name)
+		case "BLOB":
+			v, err = r.Bytes(col.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r[col.Name] = v
+	}
+	return nil
+}
+
+// schemaCache remembers table schemas for WithTypedRecords, keyed by table path.
+type schemaCache struct {
+	mu     sync.Mutex
+	tables map[string]*SchemaPayload
+}
+
+// typeRecords applies the schema of dbName.tableName to records if the client was
+// created with WithTypedRecords, fetching the schema on first use.
+func (c *Client) typeRecords(ctx context.Context, op, dbName, tableName string, records ...Record) error {
+	if c.schemas == nil || len(records) == 0 {
+		return nil
+	}
+	schema, err := c.recordSchema(ctx, op, dbName, tableName)
+	if err != nil {
+		return err
+	}
+	return applySchema(op, schema, records...)
+}
+
+// recordSchema returns the remembered schema of dbName.tableName, fetching it if needed,
+// or nil if the client doesn't type records.
+func (c *Client) recordSchema(ctx context.Context, op, dbName, tableName string) (*SchemaPayload, error) {
+	if c.schemas == nil {
+		return nil, nil
+	}
+	key, err := c.tablePath(op, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	c.schemas.mu.Lock()
+	schema := c.schemas.tables[key]
+	c.schemas.mu.Unlock()
+	if schema != nil {
+		return schema, nil
+	}
+	if schema, err = c.Tables.GetSchema(ctx, dbName, tableName); err != nil {
+		return nil, fmt.Errorf("%s: fetching schema of %s.%s to type records: %w", op, dbName, tableName, err)
+	}
+	c.schemas.mu.Lock()
+	c.schemas.tables[key] = schema
+	c.schemas.mu.Unlock()
+	return schema, nil
+}
+
+// applySchema applies schema (if not nil) to records, reporting a mismatch for op.
+func applySchema(op string, schema *SchemaPayload, records ...Record) error {
+	for _, record := range records {
+		if err := record.ApplySchema(schema); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// forgetSchemas drops the remembered schemas a write to apiPath may have changed: those
+// of the table or database written to. Record and transaction writes change no schema.
+func (c *Client) forgetSchemas(apiPath string) {
+	if c.schemas == nil {
+		return
+	}
+	p, _, _ := strings.Cut(apiPath, "?")
+	p = strings.TrimPrefix(strings.Trim(p, "/"), strings.Trim(apiVersionPath, "/"))
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	switch {
+	case segs[0] != "databases":
+		return
+	case len(segs) > 4 && segs[2] == "tables" && segs[4] == "records", len(segs) > 2 && segs[2] == "transaction":
+		return
+	case len(segs) >= 4 && segs[2] == "tables":
+		segs = segs[:4]
+	case len(segs) > 2:
+		segs = segs[:2]
+	}
+	prefix := c.getAPIPath(strings.Join(segs, "/"))
+	c.schemas.mu.Lock()
+	defer c.schemas.mu.Unlock()
+	for key := range c.schemas.tables {
+		if keyWithin(key, prefix) {
+			delete(c.schemas.tables, key)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// schemas_test.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordApplySchema(t *testing.T) {
+	schema := &SchemaPayload{Columns: []ColumnDefinition{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "price", Type: "REAL"},
+		{Name: "active", Type: "BOOLEAN"},
+		{Name: "data", Type: "BLOB"},
+		{Name: "name", Type: "TEXT"},
+		{Name: "note", Type: "TEXT"},
+	}}
+	rec := Record{"id": int64(9007199254740993), "price": int64(3), "active": int64(1), "data": "AAE=", "name": "ada", "note": nil, "extra": int64(5)}
+	if err := rec.ApplySchema(schema); err != nil {
+		t.Fatal(err)
+	}
+	want := Record{"id": int64(9007199254740993), "price": float64(3), "active": true, "data": []byte{0, 1}, "name": "ada", "note": nil, "extra": int64(5)}
+	if !reflect.DeepEqual(rec, want) {
+		t.Errorf("ApplySchema = %#v, want %#v", rec, want)
+	}
+
+	bad := Record{"price": "cheap"}
+	err := bad.ApplySchema(schema)
+	var typeErr *ColumnTypeError
+	if !errors.As(err, &typeErr) || typeErr.Column != "price" || bad["price"] != "cheap" {
+		t.Errorf("ApplySchema of a TEXT value in a REAL column = %v, record %v", err, bad)
+	}
+}
+
+func TestWithTypedRecords(t *testing.T) {
+	var schemaFetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/tables/items/schema"):
+			schemaFetches.Add(1)
+			w.Write([]byte(`{"table_name": "items", "columns": [{"name": "id", "type": "INTEGER"}, {"name": "price", "type": "REAL"}, {"name": "data", "type": "BLOB"}]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/databases/shop/schema"):
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"record_id": 2}`))
+		case strings.HasSuffix(r.URL.Path, "/records/1"):
+			w.Write([]byte(`{"id": 1, "price": 3.0, "data": "aGk="}`))
+		default:
+			w.Write([]byte(`[{"id": 1, "price": 3.0, "data": "aGk="}, {"id": 2, "price": null, "data": null}]`))
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL, WithTypedRecords())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetAuthToken("token")
+	ctx := context.Background()
+
+	rec, err := client.Records.Get(ctx, "shop", "items", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Record{"id": int64(1), "price": float64(3), "data": []byte("hi")}); !reflect.DeepEqual(rec, want) {
+		t.Errorf("Get = %#v, want %#v", rec, want)
+	}
+	recs, err := client.Records.List(ctx, "shop", "items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0]["price"] != float64(3) || recs[1]["price"] != nil {
+		t.Errorf("List = %#v", recs)
+	}
+	var streamed []Record
+	if err := client.Records.Stream(ctx, "shop", "items", nil, func(r Record) error { streamed = append(streamed, r); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(streamed, recs) {
+		t.Errorf("Stream = %#v, want %#v", streamed, recs)
+	}
+	if n := schemaFetches.Load(); n != 1 {
+		t.Errorf("schema fetched %d times, want once", n)
+	}
+
+	// Record writes keep the schema; schema writes drop it
+	if _, err := client.Records.Create(ctx, "shop", "items", map[string]interface{}{"price": 1.5}); err != nil {
+		t.Fatal(err)
+	}
+	client.Records.Get(ctx, "shop", "items", 1)
+	if n := schemaFetches.Load(); n != 1 {
+		t.Errorf("schema fetched %d times after a record write, want once", n)
+	}
+	if err := client.Databases.DefineSchema(ctx, "shop", SchemaPayload{TableName: "items", Columns: []ColumnDefinition{{Name: "price", Type: "REAL"}}}); err != nil {
+		t.Fatal(err)
+	}
+	client.Records.Get(ctx, "shop", "items", 1)
+	if n := schemaFetches.Load(); n != 2 {
+		t.Errorf("schema fetched %d times after a schema write, want twice", n)
+	}
+}
@@ -0,0 +1,93 @@
+// server.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Capability names an optional server feature reported by ServerInfo.
+type Capability string
+
+const (
+	CapabilityPagination   Capability = "pagination"   // Limit and Offset in ListRecordsOptions
+	CapabilitySorting      Capability = "sorting"      // SortBy and SortDirection in ListRecordsOptions
+	CapabilityBatch        Capability = "batch"        // RecordService.GetMany
+	CapabilityUpsert       Capability = "upsert"       // Insert-or-update writes
+	CapabilityCount        Capability = "count"        // RecordService.Count
+	CapabilityAggregate    Capability = "aggregate"    // RecordService.Aggregate
+	CapabilityBulkWrite    Capability = "bulk_write"   // RecordService.UpdateWhere and DeleteWhere
+	CapabilityTransactions Capability = "transactions" // Client.Transaction
+	CapabilityWatch        Capability = "watch"        // Streaming RecordService.Watch (otherwise it polls)
+)
+
+// legacyCapabilities are assumed for servers without the info endpoint: the features
+// the SDK has always relied on.
+var legacyCapabilities = []Capability{CapabilityPagination, CapabilitySorting}
+
+// Ping checks that the server is reachable and healthy without authenticating. It fails
+// with the transport error or the API error of an unhealthy server (e.g., 503). Servers
+// predating the health endpoint are considered healthy if they answer at all.
+func (c *Client) Ping(ctx context.Context) error {
+	err := c.doRequest(ctx, "Client.Ping", http.MethodGet, "health", nil, nil)
+	if err != nil && !endpointUnsupported(err) {
+		return err
+	}
+	return nil
+}
+
+// ServerInfo fetches the server's version and capabilities. It doesn't require
+// authentication. For servers predating the info endpoint it reports an empty Version,
+// API version "v1" and only pagination and sorting.
+//
+// The result is remembered by the client: afterwards, calls that need a capability the
+// server lacks fail at once with ErrUnsupportedByServer instead of being sent (and
+// Watch polls instead of trying to stream). Without it, such calls are sent and fail
+// with ErrUnsupportedByServer if the server doesn't know the endpoint.
+//
+//	info, err := client.ServerInfo(ctx)
+//	if err == nil && info.Supports(nebula.CapabilityBatch) { ... }
+func (c *Client) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	var info ServerInfo // Expecting {"version": "...", "api_version": "v1", "capabilities": [...]}
+	err := c.doRequest(ctx, "Client.ServerInfo", http.MethodGet, "info", nil, &info)
+	if err != nil {
+		if !endpointUnsupported(err) {
+			return nil, err
+		}
+		info = ServerInfo{APIVersion: "v1", Capabilities: legacyCapabilities}
+	}
+
+	c.infoMu.Lock()
+	c.info = &info
+	c.infoMu.Unlock()
+	result := info // Copy, so callers can't alter what gating relies on
+	result.Capabilities = append([]Capability(nil), info.Capabilities...)
+	return &result, nil
+}
+
+// lacksCapability reports whether the server is known (from an earlier ServerInfo call)
+// not to support capability.
+func (c *Client) lacksCapability(capability Capability) bool {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	return c.info != nil && !c.info.Supports(capability)
+}
+
+// requireCapability fails with ErrUnsupportedByServer if the server is known not to
+// support capability, which op needs.
+func (c *Client) requireCapability(op string, capability Capability) error {
+	if c.lacksCapability(capability) {
+		return fmt.Errorf("%s: server lacks the %q capability: %w", op, capability, ErrUnsupportedByServer)
+	}
+	return nil
+}
+
+// unsupportedByServer wraps err, returned by op, with ErrUnsupportedByServer if it shows
+// that the server doesn't implement the endpoint for capability.
+func unsupportedByServer(op string, capability Capability, err error) error {
+	if err != nil && endpointUnsupported(err) {
+		return fmt.Errorf("%s: server lacks the %q capability: %w: %w", op, capability, ErrUnsupportedByServer, err)
+	}
+	return err
+}
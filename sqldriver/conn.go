@@ -0,0 +1,436 @@
+// conn.go
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+const primaryKey = "id" // Server-assigned INTEGER primary key
+
+// conn implements driver.Conn with the context-aware query, exec and ping interfaces.
+// It holds no network resources of its own; all connections of a connector share a client.
+type conn struct {
+	client  *nebula.Client
+	dbName  string
+	schemas map[string]*nebula.SchemaPayload // Table schemas, fetched on first use
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+)
+
+// Prepare parses query; syntax errors and unsupported SQL are reported here.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext parses query; syntax errors and unsupported SQL are reported here.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	st, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, st: st}, nil
+}
+
+// Close implements driver.Conn.
+func (c *conn) Close() error { return nil }
+
+// Begin fails: the API offers no interactive transactions.
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, unsupported("transactions (use nebula.Client.Transaction for atomic batches)")
+}
+
+// QueryContext runs a SELECT.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	st, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.query(ctx, st, args)
+}
+
+// ExecContext runs an INSERT, UPDATE or DELETE.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	st, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return c.exec(ctx, st, args)
+}
+
+// Ping checks that the database is reachable with the connection's credentials.
+func (c *conn) Ping(ctx context.Context) error {
+	if _, err := c.client.Tables.List(ctx, c.dbName); err != nil {
+		return fmt.Errorf("sqldriver: ping: %w", err)
+	}
+	return nil
+}
+
+// stmt implements driver.Stmt for a parsed statement.
+type stmt struct {
+	conn *conn
+	st   *statement
+}
+
+var (
+	_ driver.StmtQueryContext = (*stmt)(nil)
+	_ driver.StmtExecContext  = (*stmt)(nil)
+)
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return s.st.params }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.exec(context.Background(), s.st, namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.exec(ctx, s.st, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.query(context.Background(), s.st, namedValues(args))
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.query(ctx, s.st, args)
+}
+
+// result implements driver.Result.
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// exec translates an INSERT, UPDATE or DELETE into record calls.
+func (c *conn) exec(ctx context.Context, st *statement, args []driver.NamedValue) (driver.Result, error) {
+	switch st.kind {
+	case stmtInsert:
+		return c.insert(ctx, st, args)
+	case stmtUpdate:
+		patch := make(map[string]interface{}, len(st.set))
+		for _, a := range st.set {
+			v, err := bind(a.value, args)
+			if err != nil {
+				return nil, err
+			}
+			patch[a.column] = v
+		}
+		id, byID, filter, err := whereClause(st.where, args)
+		if err != nil {
+			return nil, err
+		}
+		if byID {
+			res, err := c.client.Records.UpdateWithResult(ctx, c.dbName, st.table, id, patch, nil)
+			if errors.Is(err, nebula.ErrRecordNotFound) {
+				return result{}, nil // Like SQL: no matching row is not an error
+			}
+			if err != nil {
+				return nil, err
+			}
+			return result{rowsAffected: res.RowsAffected}, nil
+		}
+		n, err := c.client.Records.UpdateWhere(ctx, c.dbName, st.table, filter, patch, whereOptions(filter)...)
+		if err != nil {
+			return nil, err
+		}
+		return result{rowsAffected: n}, nil
+	case stmtDelete:
+		id, byID, filter, err := whereClause(st.where, args)
+		if err != nil {
+			return nil, err
+		}
+		if byID {
+			err := c.client.Records.Delete(ctx, c.dbName, st.table, id)
+			if errors.Is(err, nebula.ErrRecordNotFound) {
+				return result{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return result{rowsAffected: 1}, nil
+		}
+		n, err := c.client.Records.DeleteWhere(ctx, c.dbName, st.table, filter, whereOptions(filter)...)
+		if err != nil {
+			return nil, err
+		}
+		return result{rowsAffected: n}, nil
+	default:
+		return nil, errors.New("sqldriver: SELECT must be run with Query, not Exec")
+	}
+}
+
+// insert creates one record, or several atomically in a transaction.
+func (c *conn) insert(ctx context.Context, st *statement, args []driver.NamedValue) (driver.Result, error) {
+	rows := make([]map[string]interface{}, len(st.values))
+	for i, values := range st.values {
+		rows[i] = make(map[string]interface{}, len(st.columns))
+		for j, col := range st.columns {
+			v, err := bind(values[j], args)
+			if err != nil {
+				return nil, err
+			}
+			rows[i][col] = v
+		}
+	}
+
+	if len(rows) == 1 {
+		id, err := c.client.Records.Create(ctx, c.dbName, st.table, rows[0])
+		if err != nil {
+			return nil, err
+		}
+		return result{lastInsertID: id, rowsAffected: 1}, nil
+	}
+
+	var last *nebula.TxResult
+	err := c.client.Transaction(ctx, c.dbName, func(tx *nebula.Tx) error {
+		for _, row := range rows {
+			last = tx.Create(st.table, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result{lastInsertID: last.ID(), rowsAffected: int64(len(rows))}, nil
+}
+
+// query translates a SELECT into a Get, List or Count call.
+func (c *conn) query(ctx context.Context, st *statement, args []driver.NamedValue) (driver.Rows, error) {
+	if st.kind != stmtSelect {
+		return nil, errors.New("sqldriver: INSERT, UPDATE and DELETE must be run with Exec, not Query")
+	}
+	id, byID, filter, err := whereClause(st.where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if st.count {
+		if st.orderBy != "" || st.limit != nil || st.offset != nil {
+			return nil, unsupported("ORDER BY, LIMIT or OFFSET with COUNT(*)")
+		}
+		if byID {
+			filter = nebula.Filter{primaryKey: strconv.FormatInt(id, 10)}
+		}
+		n, err := c.client.Records.Count(ctx, c.dbName, st.table, filter)
+		if err != nil {
+			return nil, err
+		}
+		return &rows{cols: []column{{name: "COUNT(*)", typ: "INTEGER"}}, records: []nebula.Record{{"COUNT(*)": n}}}, nil
+	}
+
+	cols, err := c.columns(ctx, st.table, st.columns, st.orderBy)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := bindCount(st.limit, "LIMIT", args)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := bindCount(st.offset, "OFFSET", args)
+	if err != nil {
+		return nil, err
+	}
+
+	if byID {
+		record, err := c.client.Records.Get(ctx, c.dbName, st.table, id)
+		if errors.Is(err, nebula.ErrRecordNotFound) {
+			return &rows{cols: cols}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if (limit != nil && *limit == 0) || (offset != nil && *offset > 0) {
+			return &rows{cols: cols}, nil
+		}
+		return &rows{cols: cols, records: []nebula.Record{record}}, nil
+	}
+
+	opts := &nebula.ListRecordsOptions{Filters: filter, Limit: limit, Offset: offset}
+	if st.orderBy != "" {
+		dir := "asc"
+		if st.desc {
+			dir = "desc"
+		}
+		opts.SortBy, opts.SortDirection = &st.orderBy, &dir
+	}
+	records, err := c.client.Records.List(ctx, c.dbName, st.table, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{cols: cols, records: records}, nil
+}
+
+// columns resolves the selected columns (nil for *) against the table schema and checks
+// that orderBy names a column. The schema is re-fetched once if a column is unknown.
+func (c *conn) columns(ctx context.Context, table string, selected []string, orderBy string) ([]column, error) {
+	for attempt := 0; ; attempt++ {
+		schema, ok := c.schemas[table]
+		if !ok || attempt > 0 {
+			var err error
+			if schema, err = c.client.Tables.GetSchema(ctx, c.dbName, table); err != nil {
+				return nil, err
+			}
+			c.schemas[table] = schema
+		}
+
+		types := map[string]string{primaryKey: "INTEGER"}
+		all := []column{{name: primaryKey, typ: "INTEGER"}}
+		for _, def := range schema.Columns {
+			if def.Name != primaryKey {
+				typ := normalizeType(def.Type)
+				types[def.Name] = typ
+				all = append(all, column{name: def.Name, typ: typ})
+			}
+		}
+
+		missing := ""
+		if _, ok := types[orderBy]; orderBy != "" && !ok {
+			missing = orderBy
+		}
+		cols := all
+		if selected != nil {
+			cols = make([]column, 0, len(selected))
+			for _, name := range selected {
+				typ, ok := types[name]
+				if !ok {
+					missing = name
+					break
+				}
+				cols = append(cols, column{name: name, typ: typ})
+			}
+		}
+		if missing == "" {
+			return cols, nil
+		}
+		if attempt > 0 {
+			return nil, fmt.Errorf("sqldriver: no such column: %s.%s", table, missing)
+		}
+	}
+}
+
+// whereClause splits WHERE conditions into a single-record lookup (exactly `id = n`)
+// or a filter.
+func whereClause(conds []assignment, args []driver.NamedValue) (id int64, byID bool, filter nebula.Filter, err error) {
+	if len(conds) == 1 && conds[0].column == primaryKey {
+		v, err := bind(conds[0].value, args)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		switch n := v.(type) {
+		case int64:
+			return n, true, nil, nil
+		case string:
+			if id, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return id, true, nil, nil
+			}
+		}
+		return 0, false, nil, fmt.Errorf("sqldriver: id must be an integer, got %T", v)
+	}
+
+	filter = make(nebula.Filter, len(conds))
+	for _, cond := range conds {
+		v, err := bind(cond.value, args)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		s, err := filterValue(v)
+		if err != nil {
+			return 0, false, nil, err
+		}
+		if prev, ok := filter[cond.column]; ok && prev != s {
+			return 0, false, nil, unsupported("column %s compared with two different values", cond.column)
+		}
+		filter[cond.column] = s
+	}
+	return 0, false, filter, nil
+}
+
+// whereOptions allows statements without WHERE to affect every row, as in SQL.
+func whereOptions(filter nebula.Filter) []nebula.WhereOption {
+	if len(filter) == 0 {
+		return []nebula.WhereOption{nebula.AllRows()}
+	}
+	return nil
+}
+
+// filterValue renders a bound value as a filter string.
+func filterValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(t), nil
+	case nil:
+		return "", unsupported("comparison with NULL (the API filters on equality only)")
+	}
+	return "", fmt.Errorf("sqldriver: unsupported parameter type %T", v)
+}
+
+// bind returns an operand's value: the literal, or the bound argument converted to a
+// type the API accepts.
+func bind(op operand, args []driver.NamedValue) (interface{}, error) {
+	if op.param == 0 {
+		return op.value, nil
+	}
+	for _, arg := range args {
+		if arg.Name != "" {
+			return nil, unsupported("named parameters (use ? or $N)")
+		}
+		if arg.Ordinal == op.param {
+			if t, ok := arg.Value.(time.Time); ok {
+				return t.UTC().Format(time.RFC3339Nano), nil
+			}
+			return arg.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("sqldriver: missing argument for parameter %d", op.param)
+}
+
+// bindCount binds a LIMIT or OFFSET operand, which must be a non-negative integer.
+func bindCount(op *operand, clause string, args []driver.NamedValue) (*int, error) {
+	if op == nil {
+		return nil, nil
+	}
+	v, err := bind(*op, args)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return nil, fmt.Errorf("sqldriver: %s must be a non-negative integer, got %v", clause, v)
+	}
+	i := int(n)
+	return &i, nil
+}
+
+// namedValues converts positional arguments of the legacy Stmt interface.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
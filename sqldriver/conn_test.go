@@ -0,0 +1,95 @@
+// conn_test.go
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+func TestWhereClause(t *testing.T) {
+	tests := []struct {
+		query    string
+		args     []driver.Value
+		wantID   int64
+		wantByID bool
+		want     nebula.Filter
+		wantErr  error // Sentinel the error must wrap, if any
+		fails    bool
+	}{
+		{query: "DELETE FROM t WHERE id = 42", wantID: 42, wantByID: true},
+		{query: "DELETE FROM t WHERE id = ?", args: []driver.Value{int64(7)}, wantID: 7, wantByID: true},
+		{query: "DELETE FROM t WHERE id = ?", args: []driver.Value{"8"}, wantID: 8, wantByID: true},
+		{query: "DELETE FROM t WHERE id = ?", args: []driver.Value{1.5}, fails: true},
+		{query: "DELETE FROM t WHERE id = 1 AND name = 'a'", want: nebula.Filter{"id": "1", "name": "a"}},
+		{query: "DELETE FROM t WHERE name = $2 AND age = $1 AND score = 2.5 AND ok = TRUE",
+			args: []driver.Value{int64(30), "ada"}, want: nebula.Filter{"name": "ada", "age": "30", "score": "2.5", "ok": "true"}},
+		{query: "DELETE FROM t WHERE data = ?", args: []driver.Value{[]byte{0, 1}}, want: nebula.Filter{"data": "AAE="}},
+		{query: "DELETE FROM t WHERE at = ?", args: []driver.Value{time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))},
+			want: nebula.Filter{"at": "2024-05-01T10:00:00Z"}},
+		{query: "DELETE FROM t WHERE a = 1 AND a = '1'", want: nebula.Filter{"a": "1"}},
+		{query: "DELETE FROM t WHERE a = 1 AND a = 2", wantErr: ErrUnsupported},
+		{query: "DELETE FROM t WHERE a = ?", args: []driver.Value{nil}, wantErr: ErrUnsupported},
+		{query: "DELETE FROM t WHERE a = ?", args: []driver.Value{struct{}{}}, fails: true},
+		{query: "DELETE FROM t WHERE a = ? AND b = ?", args: []driver.Value{"x"}, fails: true},
+		{query: "DELETE FROM t", want: nebula.Filter{}},
+	}
+	for _, tt := range tests {
+		st, err := parse(tt.query)
+		if err != nil {
+			t.Fatalf("parse(%q) = %v", tt.query, err)
+		}
+		id, byID, filter, err := whereClause(st.where, namedValues(tt.args))
+		switch {
+		case tt.wantErr != nil || tt.fails:
+			if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("%q %v: err = %v, want %v", tt.query, tt.args, err, tt.wantErr)
+			}
+		case err != nil:
+			t.Errorf("%q %v: %v", tt.query, tt.args, err)
+		case id != tt.wantID || byID != tt.wantByID || !tt.wantByID && !reflect.DeepEqual(filter, tt.want):
+			t.Errorf("%q %v = id %d, byID %v, filter %v; want %d, %v, %v", tt.query, tt.args, id, byID, filter, tt.wantID, tt.wantByID, tt.want)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: "a"}, {Ordinal: 2, Value: int64(5)}}
+	for _, tt := range []struct {
+		op   operand
+		want interface{}
+	}{
+		{lit("x"), "x"},
+		{lit(nil), nil},
+		{arg(1), "a"},
+		{arg(2), int64(5)},
+	} {
+		if got, err := bind(tt.op, args); err != nil || got != tt.want {
+			t.Errorf("bind(%+v) = %v, %v; want %v", tt.op, got, err, tt.want)
+		}
+	}
+	if _, err := bind(arg(3), args); err == nil {
+		t.Error("bind of a parameter without argument succeeded")
+	}
+	named := []driver.NamedValue{{Name: "name", Ordinal: 1, Value: "a"}}
+	if _, err := bind(arg(1), named); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("bind with a named argument = %v, want ErrUnsupported", err)
+	}
+
+	limit := lit(int64(10))
+	if n, err := bindCount(&limit, "LIMIT", nil); err != nil || *n != 10 {
+		t.Errorf("bindCount(10) = %v, %v", n, err)
+	}
+	if n, err := bindCount(nil, "LIMIT", nil); err != nil || n != nil {
+		t.Errorf("bindCount(nil) = %v, %v", n, err)
+	}
+	for _, v := range []driver.Value{int64(-1), "10", 2.5} {
+		if _, err := bindCount(&operand{param: 1}, "LIMIT", namedValues([]driver.Value{v})); err == nil {
+			t.Errorf("bindCount(%v) succeeded", v)
+		}
+	}
+}
@@ -0,0 +1,596 @@
+// parse.go
+package sqldriver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// stmtKind identifies the type of a parsed statement.
+type stmtKind int
+
+const (
+	stmtSelect stmtKind = iota
+	stmtInsert
+	stmtUpdate
+	stmtDelete
+)
+
+// statement is a parsed SQL statement of the supported subset.
+type statement struct {
+	kind    stmtKind
+	table   string
+	columns []string     // SELECT list (nil for *) or INSERT column list
+	count   bool         // SELECT COUNT(*)
+	where   []assignment // Equality conditions, combined with AND
+	orderBy string       // ORDER BY column ("" if none)
+	desc    bool         // ORDER BY ... DESC
+	limit   *operand     // LIMIT (nil if none)
+	offset  *operand     // OFFSET (nil if none)
+	values  [][]operand  // INSERT VALUES rows
+	set     []assignment // UPDATE SET list
+	params  int          // Number of parameters the statement takes
+}
+
+// operand is a literal value or a reference to a bound parameter.
+type operand struct {
+	param int         // 1-based parameter ordinal; 0 for a literal
+	value interface{} // Literal: string, int64, float64, bool or nil
+}
+
+// assignment pairs a column with a value: `col = value` in SET and WHERE clauses.
+type assignment struct {
+	column string
+	value  operand
+}
+
+// tokenKind identifies the type of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // Unquoted identifier or keyword
+	tokQuoted           // "quoted" or `quoted` identifier
+	tokString           // 'string literal'
+	tokNumber           // Integer or decimal literal
+	tokParam            // ? or $N
+	tokSymbol           // Punctuation and operators
+)
+
+type token struct {
+	kind tokenKind
+	text string // Unquoted text for identifiers and strings
+	pos  int    // Byte offset in the query
+}
+
+// tokenize splits query into tokens.
+func tokenize(query string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' { // Line comment
+				i++
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(query) && isIdentPart(query[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokWord, text: query[start:i], pos: start})
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9'):
+			start := i
+			for i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.' || query[i] == 'e' || query[i] == 'E' ||
+				((query[i] == '+' || query[i] == '-') && (query[i-1] == 'e' || query[i-1] == 'E'))) {
+				i++
+			}
+			toks = append(toks, token{kind: tokNumber, text: query[start:i], pos: start})
+		case c == '\'' || c == '"' || c == '`':
+			start := i
+			text, n, ok := unquote(query[i:], c)
+			if !ok {
+				return nil, fmt.Errorf("sqldriver: syntax error: unterminated quote at offset %d", start)
+			}
+			i += n
+			kind := tokQuoted
+			if c == '\'' {
+				kind = tokString
+			}
+			toks = append(toks, token{kind: kind, text: text, pos: start})
+		case c == '?':
+			toks = append(toks, token{kind: tokParam, text: "?", pos: i})
+			i++
+		case c == '$':
+			start := i
+			i++
+			for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("sqldriver: syntax error: expected parameter number after $ at offset %d", start)
+			}
+			toks = append(toks, token{kind: tokParam, text: query[start:i], pos: start})
+		default:
+			start := i
+			for _, op := range []string{"!=", "<>", "<=", ">="} { // Recognised only to reject them clearly
+				if strings.HasPrefix(query[i:], op) {
+					i += len(op)
+					break
+				}
+			}
+			if i == start {
+				i++
+			}
+			toks = append(toks, token{kind: tokSymbol, text: query[start:i], pos: start})
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(query)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// unquote reads a quoted string starting at s[0] == q, where a doubled quote escapes
+// itself. It returns the unquoted text and the number of bytes consumed.
+func unquote(s string, q byte) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != q {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == q {
+			b.WriteByte(q)
+			i++
+			continue
+		}
+		return b.String(), i + 1, true
+	}
+	return "", 0, false
+}
+
+// parser is a recursive-descent parser over the token stream.
+type parser struct {
+	toks     []token
+	pos      int
+	query    string
+	ordinal  int  // Next ordinal for ? parameters
+	question bool // ? parameters seen
+	dollar   bool // $N parameters seen
+	params   int  // Highest parameter ordinal
+}
+
+// parse parses query into a statement, returning an error wrapping ErrUnsupported for
+// valid SQL outside the supported subset.
+func parse(query string) (*statement, error) {
+	toks, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, query: query}
+
+	var st *statement
+	switch first := p.peek(); {
+	case p.isKeyword(first, "SELECT"):
+		st, err = p.parseSelect()
+	case p.isKeyword(first, "INSERT"):
+		st, err = p.parseInsert()
+	case p.isKeyword(first, "UPDATE"):
+		st, err = p.parseUpdate()
+	case p.isKeyword(first, "DELETE"):
+		st, err = p.parseDelete()
+	case first.kind == tokEOF:
+		return nil, fmt.Errorf("sqldriver: empty query")
+	default:
+		return nil, unsupported("%s statements", strings.ToUpper(first.text))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.acceptSymbol(";")
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.unexpected(tok, "end of statement")
+	}
+	st.params = p.params
+	return st, nil
+}
+
+// parseSelect parses
+// SELECT {* | COUNT(*) | col, ...} FROM table [WHERE ...] [ORDER BY col [ASC|DESC]] [LIMIT n] [OFFSET n].
+func (p *parser) parseSelect() (*statement, error) {
+	p.next() // SELECT
+	st := &statement{kind: stmtSelect}
+	if p.isKeyword(p.peek(), "DISTINCT") {
+		return nil, unsupported("SELECT DISTINCT")
+	}
+	switch {
+	case p.acceptSymbol("*"):
+	case p.isKeyword(p.peek(), "COUNT"):
+		p.next()
+		if !p.acceptSymbol("(") || !p.acceptSymbol("*") || !p.acceptSymbol(")") {
+			return nil, unsupported("COUNT other than COUNT(*)")
+		}
+		st.count = true
+	default:
+		for {
+			col, err := p.identifier("column name")
+			if err != nil {
+				return nil, err
+			}
+			if p.peek().text == "(" {
+				return nil, unsupported("function %s()", strings.ToUpper(col))
+			}
+			if p.isKeyword(p.peek(), "AS") {
+				return nil, unsupported("column aliases")
+			}
+			st.columns = append(st.columns, col)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.identifier("table name")
+	if err != nil {
+		return nil, err
+	}
+	st.table = table
+	if next := p.peek(); next.text == "," || p.isKeyword(next, "JOIN") || p.isKeyword(next, "INNER") ||
+		p.isKeyword(next, "LEFT") || p.isKeyword(next, "RIGHT") || p.isKeyword(next, "CROSS") {
+		return nil, unsupported("queries over more than one table")
+	}
+
+	if st.where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	if p.isKeyword(p.peek(), "GROUP") || p.isKeyword(p.peek(), "HAVING") {
+		return nil, unsupported("GROUP BY and HAVING")
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if st.orderBy, err = p.identifier("column name"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("DESC") {
+			st.desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		if p.peek().text == "," {
+			return nil, unsupported("ORDER BY more than one column")
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		v, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		st.limit = &v
+	}
+	if p.acceptKeyword("OFFSET") {
+		v, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		st.offset = &v
+	}
+	return st, nil
+}
+
+// parseInsert parses INSERT INTO table (col, ...) VALUES (value, ...)[, (value, ...)].
+func (p *parser) parseInsert() (*statement, error) {
+	p.next() // INSERT
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	table, err := p.identifier("table name")
+	if err != nil {
+		return nil, err
+	}
+	st := &statement{kind: stmtInsert, table: table}
+
+	if !p.acceptSymbol("(") {
+		return nil, unsupported("INSERT without a column list")
+	}
+	for {
+		col, err := p.identifier("column name")
+		if err != nil {
+			return nil, err
+		}
+		st.columns = append(st.columns, col)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword(p.peek(), "SELECT") {
+		return nil, unsupported("INSERT ... SELECT")
+	}
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		var row []operand
+		for {
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		if len(row) != len(st.columns) {
+			return nil, fmt.Errorf("sqldriver: INSERT has %d columns but %d values", len(st.columns), len(row))
+		}
+		st.values = append(st.values, row)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if p.isKeyword(p.peek(), "ON") || p.isKeyword(p.peek(), "RETURNING") {
+		return nil, unsupported("%s clauses", strings.ToUpper(p.peek().text))
+	}
+	return st, nil
+}
+
+// parseUpdate parses UPDATE table SET col = value, ... [WHERE ...].
+func (p *parser) parseUpdate() (*statement, error) {
+	p.next() // UPDATE
+	table, err := p.identifier("table name")
+	if err != nil {
+		return nil, err
+	}
+	st := &statement{kind: stmtUpdate, table: table}
+	if err := p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		a, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		st.set = append(st.set, a)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	if st.where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// parseDelete parses DELETE FROM table [WHERE ...].
+func (p *parser) parseDelete() (*statement, error) {
+	p.next() // DELETE
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.identifier("table name")
+	if err != nil {
+		return nil, err
+	}
+	st := &statement{kind: stmtDelete, table: table}
+	if st.where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// parseWhere parses an optional WHERE clause of equality conditions joined by AND.
+func (p *parser) parseWhere() ([]assignment, error) {
+	if !p.acceptKeyword("WHERE") {
+		return nil, nil
+	}
+	var conds []assignment
+	for {
+		if p.peek().text == "(" {
+			return nil, unsupported("parenthesised conditions")
+		}
+		if p.isKeyword(p.peek(), "NOT") {
+			return nil, unsupported("NOT conditions")
+		}
+		a, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		if a.value.param == 0 && a.value.value == nil {
+			return nil, unsupported("comparison with NULL (the API filters on equality only)")
+		}
+		conds = append(conds, a)
+		if p.isKeyword(p.peek(), "OR") {
+			return nil, unsupported("OR conditions")
+		}
+		if !p.acceptKeyword("AND") {
+			break
+		}
+	}
+	return conds, nil
+}
+
+// assignment parses `column = operand`.
+func (p *parser) assignment() (assignment, error) {
+	col, err := p.identifier("column name")
+	if err != nil {
+		return assignment{}, err
+	}
+	op := p.peek()
+	if op.text != "=" {
+		if op.kind == tokSymbol || p.isKeyword(op, "IS") || p.isKeyword(op, "IN") || p.isKeyword(op, "LIKE") || p.isKeyword(op, "BETWEEN") {
+			return assignment{}, unsupported("operator %s (only = is supported)", strings.ToUpper(op.text))
+		}
+		return assignment{}, p.unexpected(op, "=")
+	}
+	p.next()
+	v, err := p.operand()
+	if err != nil {
+		return assignment{}, err
+	}
+	return assignment{column: col, value: v}, nil
+}
+
+// operand parses a literal or parameter.
+func (p *parser) operand() (operand, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokParam:
+		return p.param(tok)
+	case tokString:
+		return operand{value: tok.text}, nil
+	case tokNumber:
+		return parseNumber(tok.text)
+	case tokSymbol:
+		if tok.text == "-" && p.peek().kind == tokNumber {
+			v, err := parseNumber("-" + p.next().text)
+			return v, err
+		}
+	case tokWord:
+		switch strings.ToUpper(tok.text) {
+		case "NULL":
+			return operand{value: nil}, nil
+		case "TRUE":
+			return operand{value: true}, nil
+		case "FALSE":
+			return operand{value: false}, nil
+		}
+		if p.peek().text == "(" {
+			return operand{}, unsupported("function %s()", strings.ToUpper(tok.text))
+		}
+		return operand{}, unsupported("column references and expressions as values (%q)", tok.text)
+	}
+	return operand{}, p.unexpected(tok, "a value")
+}
+
+// param resolves a ? or $N token to its ordinal. The two styles can't be mixed.
+func (p *parser) param(tok token) (operand, error) {
+	var ordinal int
+	if tok.text == "?" {
+		p.question = true
+		p.ordinal++
+		ordinal = p.ordinal
+	} else {
+		p.dollar = true
+		n, err := strconv.Atoi(tok.text[1:])
+		if err != nil || n < 1 {
+			return operand{}, fmt.Errorf("sqldriver: invalid parameter %s", tok.text)
+		}
+		ordinal = n
+	}
+	if p.question && p.dollar {
+		return operand{}, fmt.Errorf("sqldriver: cannot mix ? and $N parameters")
+	}
+	if ordinal > p.params {
+		p.params = ordinal
+	}
+	return operand{param: ordinal}, nil
+}
+
+// parseNumber parses an integer or decimal literal.
+func parseNumber(text string) (operand, error) {
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return operand{value: i}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return operand{}, fmt.Errorf("sqldriver: syntax error: invalid number %q", text)
+	}
+	return operand{value: f}, nil
+}
+
+// identifier parses a plain or quoted identifier.
+func (p *parser) identifier(what string) (string, error) {
+	tok := p.peek()
+	if tok.kind != tokWord && tok.kind != tokQuoted {
+		return "", p.unexpected(tok, what)
+	}
+	p.next()
+	if tok.kind == tokWord && p.acceptSymbol(".") {
+		return "", unsupported("qualified names (%s.%s)", tok.text, p.peek().text)
+	}
+	return tok.text, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(tok token, kw string) bool {
+	return tok.kind == tokWord && strings.EqualFold(tok.text, kw)
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(p.peek(), kw) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.unexpected(p.peek(), kw)
+	}
+	return nil
+}
+
+func (p *parser) acceptSymbol(sym string) bool {
+	if tok := p.peek(); tok.kind == tokSymbol && tok.text == sym {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(sym string) error {
+	if !p.acceptSymbol(sym) {
+		return p.unexpected(p.peek(), sym)
+	}
+	return nil
+}
+
+// unexpected returns a syntax error for tok, which isn't what was expected.
+func (p *parser) unexpected(tok token, want string) error {
+	if tok.kind == tokEOF {
+		return fmt.Errorf("sqldriver: syntax error: expected %s at end of query", want)
+	}
+	return fmt.Errorf("sqldriver: syntax error at offset %d: expected %s, found %q", tok.pos, want, p.query[tok.pos:min(tok.pos+20, len(p.query))])
+}
+
+// unsupported returns an error wrapping ErrUnsupported.
+func unsupported(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,103 @@
+// parse_test.go
+package sqldriver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func lit(v interface{}) operand { return operand{value: v} }
+func arg(n int) operand         { return operand{param: n} }
+
+func TestParse(t *testing.T) {
+	two := lit(int64(2))
+	tests := []struct {
+		query string
+		want  *statement
+	}{
+		{"SELECT * FROM users", &statement{kind: stmtSelect, table: "users"}},
+		{"select id, \"full name\" from `users`;", &statement{kind: stmtSelect, table: "users", columns: []string{"id", "full name"}}},
+		{"SELECT COUNT(*) FROM users WHERE active = TRUE", &statement{kind: stmtSelect, table: "users", count: true,
+			where: []assignment{{"active", lit(true)}}}},
+		{"SELECT name FROM users WHERE name = 'O''Brien' AND age = -3 -- comment", &statement{kind: stmtSelect, table: "users",
+			columns: []string{"name"}, where: []assignment{{"name", lit("O'Brien")}, {"age", lit(int64(-3))}}}},
+		{"SELECT * FROM users WHERE score = 1.5e2 ORDER BY name DESC LIMIT ? OFFSET ?", &statement{kind: stmtSelect, table: "users",
+			where: []assignment{{"score", lit(150.0)}}, orderBy: "name", desc: true, limit: &operand{param: 1}, offset: &operand{param: 2}, params: 2}},
+		{"SELECT * FROM users ORDER BY id ASC LIMIT 2", &statement{kind: stmtSelect, table: "users", orderBy: "id", limit: &two}},
+		{"INSERT INTO users (name, age) VALUES ($1, $2), ('bob', NULL)", &statement{kind: stmtInsert, table: "users",
+			columns: []string{"name", "age"}, values: [][]operand{{arg(1), arg(2)}, {lit("bob"), lit(nil)}}, params: 2}},
+		{"UPDATE users SET name = ?, active = FALSE WHERE id = ?", &statement{kind: stmtUpdate, table: "users",
+			set: []assignment{{"name", arg(1)}, {"active", lit(false)}}, where: []assignment{{"id", arg(2)}}, params: 2}},
+		{"UPDATE users SET age = $2 WHERE name = $1 AND city = $1", &statement{kind: stmtUpdate, table: "users",
+			set: []assignment{{"age", arg(2)}}, where: []assignment{{"name", arg(1)}, {"city", arg(1)}}, params: 2}},
+		{"DELETE FROM users", &statement{kind: stmtDelete, table: "users"}},
+		{"delete from users where id = 7", &statement{kind: stmtDelete, table: "users", where: []assignment{{"id", lit(int64(7))}}}},
+	}
+	for _, tt := range tests {
+		got, err := parse(tt.query)
+		if err != nil {
+			t.Errorf("parse(%q) = %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parse(%q) =\n  %+v\nwant\n  %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query       string
+		unsupported bool // Valid SQL the driver can't translate, rather than a syntax error
+	}{
+		// Outside the supported subset
+		{"CREATE TABLE users (id INTEGER)", true},
+		{"SELECT DISTINCT name FROM users", true},
+		{"SELECT COUNT(name) FROM users", true},
+		{"SELECT upper(name) FROM users", true},
+		{"SELECT name AS n FROM users", true},
+		{"SELECT * FROM users, orders", true},
+		{"SELECT * FROM users JOIN orders ON users.id = orders.user_id", true},
+		{"SELECT * FROM public.users", true},
+		{"SELECT * FROM users WHERE age > 3", true},
+		{"SELECT * FROM users WHERE age != 3", true},
+		{"SELECT * FROM users WHERE name LIKE 'a%'", true},
+		{"SELECT * FROM users WHERE name IS NULL", true},
+		{"SELECT * FROM users WHERE name = NULL", true},
+		{"SELECT * FROM users WHERE a = 1 OR b = 2", true},
+		{"SELECT * FROM users WHERE NOT a = 1", true},
+		{"SELECT * FROM users WHERE (a = 1)", true},
+		{"SELECT * FROM users WHERE a = b", true},
+		{"SELECT * FROM users WHERE a = now()", true},
+		{"SELECT city FROM users GROUP BY city", true},
+		{"SELECT * FROM users ORDER BY a, b", true},
+		{"INSERT INTO users VALUES (1)", true},
+		{"INSERT INTO users (name) SELECT name FROM people", true},
+		{"INSERT INTO users (name) VALUES ('a') RETURNING id", true},
+		{"INSERT INTO users (name) VALUES ('a') ON CONFLICT DO NOTHING", true},
+
+		// Malformed
+		{"", false},
+		{"SELECT * FROM", false},
+		{"SELECT * users", false},
+		{"SELECT * FROM users WHERE name = 'open", false},
+		{"SELECT * FROM users WHERE a = ? AND b = $2", false},
+		{"SELECT * FROM users WHERE a = $0", false},
+		{"SELECT * FROM users WHERE a = $", false},
+		{"SELECT * FROM users LIMIT 1 garbage", false},
+		{"INSERT INTO users (a, b) VALUES (1)", false},
+		{"UPDATE users name = 1", false},
+		{"DELETE users", false},
+	}
+	for _, tt := range tests {
+		_, err := parse(tt.query)
+		if err == nil {
+			t.Errorf("parse(%q) succeeded, want an error", tt.query)
+			continue
+		}
+		if got := errors.Is(err, ErrUnsupported); got != tt.unsupported {
+			t.Errorf("parse(%q) = %v; wraps ErrUnsupported = %v, want %v", tt.query, err, got, tt.unsupported)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// rows.go
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// column is a result column with its declared type (upper-case, e.g. "INTEGER").
+type column struct {
+	name string
+	typ  string
+}
+
+// normalizeType upper-cases a schema type name.
+func normalizeType(typ string) string {
+	return strings.ToUpper(strings.TrimSpace(typ))
+}
+
+// rows implements driver.Rows over fetched records, reporting column types from the
+// table schema.
+type rows struct {
+	cols    []column
+	records []nebula.Record
+	pos     int
+}
+
+var (
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*rows)(nil)
+)
+
+// Columns returns the column names.
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.cols))
+	for i, col := range r.cols {
+		names[i] = col.name
+	}
+	return names
+}
+
+// Close implements driver.Rows.
+func (r *rows) Close() error {
+	r.records = nil
+	return nil
+}
+
+// Next converts the next record's values to the column types: INTEGER → int64,
+// REAL → float64, BOOLEAN → bool, BLOB → []byte and TEXT → string. NULL is nil.
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.records) {
+		return io.EOF
+	}
+	record := r.records[r.pos]
+	r.pos++
+	for i, col := range r.cols {
+		v, err := columnValue(record, col)
+		if err != nil {
+			return fmt.Errorf("sqldriver: record %v: %w", record[primaryKey], err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName returns the schema type of column i (e.g., "INTEGER").
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.cols[i].typ
+}
+
+// ColumnTypeScanType returns the Go type of column i's non-NULL values.
+func (r *rows) ColumnTypeScanType(i int) reflect.Type {
+	switch r.cols[i].typ {
+	case "INTEGER":
+		return reflect.TypeOf(int64(0))
+	case "REAL":
+		return reflect.TypeOf(float64(0))
+	case "BOOLEAN":
+		return reflect.TypeOf(false)
+	case "BLOB":
+		return reflect.TypeOf([]byte(nil))
+	case "TEXT":
+		return reflect.TypeOf("")
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+// ColumnTypeNullable reports that every column except the primary key is nullable.
+func (r *rows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	return r.cols[i].name != primaryKey, true
+}
+
+// columnValue converts record's value for col to its driver.Value.
+func columnValue(record nebula.Record, col column) (driver.Value, error) {
+	if v, ok := record[col.name]; !ok || v == nil {
+		return nil, nil
+	}
+	switch col.typ {
+	case "INTEGER":
+		return record.Int64(col.name)
+	case "REAL":
+		return record.Float(col.name)
+	case "BOOLEAN":
+		return record.Bool(col.name)
+	case "BLOB":
+		return record.Bytes(col.name)
+	}
+	switch v := record[col.name].(type) {
+	case string, int64, float64, bool:
+		return v, nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,207 @@
+// table.go
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+const primaryKey = "id" // Server-assigned INTEGER primary key, preserved across copies
+
+// errReachedWatermark stops an incremental source stream at the first unchanged row.
+var errReachedWatermark = errors.New("reached incremental watermark")
+
+// tableCopy copies one table.
+type tableCopy struct {
+	src, dst     *nebula.Client
+	srcDB, dstDB string
+	opts         Options
+	result       TableResult
+	perRow       bool // Set when the destination has no transaction endpoint
+}
+
+// run creates the destination table if needed and copies the rows.
+func (c *tableCopy) run(ctx context.Context) error {
+	table := c.result.Table
+	schema, err := c.src.Tables.GetSchema(ctx, c.srcDB, table)
+	if err != nil {
+		return fmt.Errorf("sync: reading schema of %s.%s: %w", c.srcDB, table, err)
+	}
+	if err := c.ensureSchema(ctx, schema); err != nil {
+		return err
+	}
+
+	// Full copies go in id order; incremental copies newest first, down to the watermark
+	sortBy, sortDir := primaryKey, "asc"
+	var watermark time.Time
+	if col := c.opts.UpdatedAtColumn; col != "" && hasColumn(schema, col) && !c.result.Created {
+		var found bool
+		watermark, found, err = c.watermark(ctx)
+		if err != nil {
+			return err
+		}
+		if found {
+			c.result.Incremental = true
+			sortBy, sortDir = col, "desc"
+		}
+	}
+
+	batch := make([]nebula.Record, 0, c.opts.BatchSize)
+	err = c.src.Records.Stream(ctx, c.srcDB, table, &nebula.ListRecordsOptions{SortBy: &sortBy, SortDirection: &sortDir}, func(record nebula.Record) error {
+		if c.result.Incremental {
+			if record.IsNull(c.opts.UpdatedAtColumn) {
+				return errReachedWatermark // NULLs sort last when descending: no changed rows remain
+			}
+			updatedAt, err := record.Time(c.opts.UpdatedAtColumn)
+			if err != nil {
+				return fmt.Errorf("sync: row %v of %s.%s: %w", record[primaryKey], c.srcDB, table, err)
+			}
+			if updatedAt.Before(watermark) {
+				return errReachedWatermark
+			}
+		}
+		batch = append(batch, record)
+		if len(batch) < c.opts.BatchSize {
+			return nil
+		}
+		err := c.write(ctx, batch)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil && !errors.Is(err, errReachedWatermark) {
+		return fmt.Errorf("sync: copying %s.%s: %w", c.srcDB, table, err)
+	}
+	if len(batch) > 0 {
+		if err := c.write(ctx, batch); err != nil {
+			return fmt.Errorf("sync: copying %s.%s: %w", c.srcDB, table, err)
+		}
+	}
+	return nil
+}
+
+// ensureSchema creates the destination table from schema if it doesn't exist, or checks
+// that the existing table has every source column.
+func (c *tableCopy) ensureSchema(ctx context.Context, schema *nebula.SchemaPayload) error {
+	table := c.result.Table
+	existing, err := c.dst.Tables.GetSchema(ctx, c.dstDB, table)
+	if errors.Is(err, nebula.ErrNotFound) {
+		define := nebula.SchemaPayload{TableName: table}
+		for _, col := range schema.Columns {
+			if col.Name != primaryKey { // Some servers list the primary key; it is implicit on create
+				define.Columns = append(define.Columns, col)
+			}
+		}
+		if err := c.dst.Databases.DefineSchema(ctx, c.dstDB, define); err != nil {
+			return fmt.Errorf("sync: creating table %s.%s: %w", c.dstDB, table, err)
+		}
+		c.result.Created = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("sync: reading schema of %s.%s: %w", c.dstDB, table, err)
+	}
+
+	var missing []string
+	for _, col := range schema.Columns {
+		if col.Name != primaryKey && !hasColumn(existing, col.Name) {
+			missing = append(missing, col.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("sync: table %s.%s lacks source columns %v", c.dstDB, table, missing)
+	}
+	return nil
+}
+
+// watermark returns the newest UpdatedAtColumn value in the destination table, and false
+// if the table has no rows with a value.
+func (c *tableCopy) watermark(ctx context.Context) (time.Time, bool, error) {
+	col := c.opts.UpdatedAtColumn
+	limit, sortDir := 1, "desc"
+	newest, err := c.dst.Records.List(ctx, c.dstDB, c.result.Table, &nebula.ListRecordsOptions{Limit: &limit, SortBy: &col, SortDirection: &sortDir})
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("sync: reading watermark of %s.%s: %w", c.dstDB, c.result.Table, err)
+	}
+	if len(newest) == 0 || newest[0].IsNull(col) {
+		return time.Time{}, false, nil
+	}
+	t, err := newest[0].Time(col)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("sync: reading watermark of %s.%s: %w", c.dstDB, c.result.Table, err)
+	}
+	return t, true, nil
+}
+
+// write inserts a batch atomically in a transaction. If some rows already exist (or the
+// copy is incremental, where most rows do), rows are upserted one by one instead.
+func (c *tableCopy) write(ctx context.Context, batch []nebula.Record) error {
+	if !c.perRow && !c.result.Incremental {
+		err := c.dst.Transaction(ctx, c.dstDB, func(tx *nebula.Tx) error {
+			for _, record := range batch {
+				tx.Create(c.result.Table, record)
+			}
+			return nil
+		})
+		switch {
+		case err == nil:
+			c.copied(int64(len(batch)))
+			return nil
+		case errors.Is(err, nebula.ErrUnsupportedByServer):
+			c.perRow = true
+		case !errors.Is(err, nebula.ErrConflict):
+			return err
+		}
+	}
+
+	for _, record := range batch {
+		if err := c.upsert(ctx, record); err != nil {
+			return fmt.Errorf("row %v: %w", record[primaryKey], err)
+		}
+	}
+	c.copied(int64(len(batch)))
+	return nil
+}
+
+// upsert updates the destination row with record's id, or inserts record if there is none.
+func (c *tableCopy) upsert(ctx context.Context, record nebula.Record) error {
+	id, err := record.Int64(primaryKey)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]interface{}, len(record))
+	for k, v := range record {
+		if k != primaryKey {
+			data[k] = v
+		}
+	}
+	if len(data) > 0 {
+		err = c.dst.Records.Update(ctx, c.dstDB, c.result.Table, id, data)
+		if !errors.Is(err, nebula.ErrRecordNotFound) {
+			return err
+		}
+	}
+	_, err = c.dst.Records.Create(ctx, c.dstDB, c.result.Table, record)
+	return err
+}
+
+// copied counts written rows and reports progress.
+func (c *tableCopy) copied(n int64) {
+	c.result.Copied += n
+	if c.opts.Progress != nil {
+		c.opts.Progress(c.result.Table, c.result.Copied)
+	}
+}
+
+// hasColumn reports whether schema declares a column named name.
+func hasColumn(schema *nebula.SchemaPayload, name string) bool {
+	for _, col := range schema.Columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,7 @@ package nebula
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 )
 
 // TableService provides methods for interacting with table-level endpoints
@@ -17,16 +14,15 @@ type TableService struct {
 
 // List retrieves the names of all tables within a specific database.
 func (s *TableService) List(ctx context.Context, dbName string) ([]string, error) {
-	if strings.TrimSpace(dbName) == "" {
-		return nil, errors.New("database name cannot be empty")
+	apiPath, err := s.client.databasePath("Tables.List", dbName, "tables")
+	if err != nil {
+		return nil, err
 	}
-
-	apiPath := s.client.getAPIPath(fmt.Sprintf("databases/%s/tables", dbName))
 	var result ListTablesResponse // Expecting {"tables": ["name1", ...]}
 
-	err := s.client.doRequest(ctx, http.MethodGet, apiPath, nil, &result)
+	err = s.client.doRequest(ctx, "Tables.List", http.MethodGet, apiPath, nil, &result)
 	if err != nil {
-		// Handle potential ErrNotFound if dbName doesn't exist
+		// doRequest maps 404 to ErrDatabaseNotFound if dbName doesn't exist
 		return nil, err
 	}
 
@@ -38,16 +34,34 @@ func (s *TableService) List(ctx context.Context, dbName string) ([]string, error
 
 // Delete drops a specific table within a database.
 func (s *TableService) Delete(ctx context.Context, dbName, tableName string) error {
-	if strings.TrimSpace(dbName) == "" || strings.TrimSpace(tableName) == "" {
-		return errors.New("database name and table name cannot be empty")
+	apiPath, err := s.client.tablePath("Tables.Delete", dbName, tableName)
+	if err != nil {
+		return err
 	}
 
-	apiPath := s.client.getAPIPath(fmt.Sprintf("databases/%s/tables/%s", dbName, tableName))
-
-	err := s.client.doRequest(ctx, http.MethodDelete, apiPath, nil, nil)
+	err = s.client.doRequest(ctx, "Tables.Delete", http.MethodDelete, apiPath, nil, nil)
 	if err != nil {
-		// Handle potential ErrNotFound if dbName or tableName doesn't exist
+		// doRequest maps 404 to ErrTableNotFound (or ErrDatabaseNotFound via server error code)
 		return err
 	}
 	return nil // Success (204)
 }
+
+// GetSchema retrieves the column definitions of a table, in table order.
+func (s *TableService) GetSchema(ctx context.Context, dbName, tableName string) (*SchemaPayload, error) {
+	apiPath, err := s.client.tablePath("Tables.GetSchema", dbName, tableName, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	var result SchemaPayload // Expecting {"table_name": "...", "columns": [{"name": "...", "type": "..."}, ...]}
+	err = s.client.doRequest(ctx, "Tables.GetSchema", http.MethodGet, apiPath, nil, &result)
+	if err != nil {
+		// doRequest maps 404 to ErrTableNotFound (or ErrDatabaseNotFound via server error code)
+		return nil, err
+	}
+	if result.TableName == "" {
+		result.TableName = tableName
+	}
+	return &result, nil
+}
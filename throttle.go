@@ -0,0 +1,179 @@
+// throttle.go
+package nebula
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	throttleMinRateFactor = 1.0 / 16 // Lowest fraction of the configured rate that 429 slowdown goes to
+	throttleRecoverSteps  = 20       // Successful requests needed to regain one halving of the rate
+)
+
+// ThrottleStats reports client-side rate limiting and concurrency capping
+// (see WithRateLimit and WithMaxConcurrentRequests).
+type ThrottleStats struct {
+	RateLimitWaits   int64         // Requests that had to wait for a rate limit token
+	RateLimitWait    time.Duration // Total time spent waiting for tokens
+	ConcurrencyWaits int64         // Requests that had to wait for a free concurrency slot
+	ConcurrencyWait  time.Duration // Total time spent waiting for slots
+	Throttled        int64         // 429 Too Many Requests responses received
+	CurrentRate      float64       // Requests per second currently allowed (0 = no rate limit)
+	InFlight         int           // Requests currently holding a concurrency slot
+}
+
+// ThrottleStats returns a snapshot of the client's throttling counters.
+func (c *Client) ThrottleStats() ThrottleStats {
+	var s ThrottleStats
+	if l := c.limiter; l != nil {
+		l.mu.Lock()
+		s.CurrentRate = l.rate
+		l.mu.Unlock()
+		s.RateLimitWaits = l.waits.Load()
+		s.RateLimitWait = time.Duration(l.waitNanos.Load())
+		s.Throttled = l.throttled.Load()
+	}
+	if sem := c.concurrency; sem != nil {
+		s.ConcurrencyWaits = sem.waits.Load()
+		s.ConcurrencyWait = time.Duration(sem.waitNanos.Load())
+		s.InFlight = len(sem.slots)
+	}
+	return s
+}
+
+// rateLimiter is a token bucket allowing rate requests per second with bursts of up to
+// burst requests. A 429 response halves the rate (down to 1/16 of the configured rate)
+// and pauses all requests for the server's Retry-After; each success wins back part of
+// the configured rate.
+type rateLimiter struct {
+	baseRate float64 // Configured rate (requests per second)
+	burst    float64
+
+	mu          sync.Mutex
+	rate        float64   // Current rate, lowered after 429 responses
+	tokens      float64   // May go negative: tokens reserved by waiting requests
+	last        time.Time // When tokens was last brought up to date
+	pausedUntil time.Time // No token is granted before this (Retry-After)
+
+	waits     atomic.Int64
+	waitNanos atomic.Int64
+	throttled atomic.Int64
+}
+
+// newRateLimiter creates a full rateLimiter.
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{baseRate: rps, burst: float64(burst), rate: rps, tokens: float64(burst), last: time.Now()}
+}
+
+// advance adds the tokens accrued since l.last. l.mu must be held.
+func (l *rateLimiter) advance(now time.Time) {
+	if now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+}
+
+// wait blocks until the request may be sent. It reserves a token up front and gives it
+// back if ctx ends first; it fails at once if ctx's deadline is before the token is due.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	now := time.Now()
+	l.mu.Lock()
+	l.advance(now)
+	var delay time.Duration
+	if l.tokens < 1 {
+		delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	if pause := l.pausedUntil.Sub(now); pause > delay {
+		delay = pause
+	}
+	if deadline, ok := ctx.Deadline(); ok && delay > 0 && now.Add(delay).After(deadline) {
+		l.mu.Unlock()
+		return fmt.Errorf("rate limit wait of %v exceeds the context deadline: %w", delay.Round(time.Millisecond), context.DeadlineExceeded)
+	}
+	l.tokens-- // Reserve
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	l.waits.Add(1)
+	start := time.Now()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		l.waitNanos.Add(int64(time.Since(start)))
+		return nil
+	case <-ctx.Done():
+		l.waitNanos.Add(int64(time.Since(start)))
+		l.mu.Lock()
+		l.tokens++ // Return the reservation
+		l.mu.Unlock()
+		return fmt.Errorf("waiting for rate limit: %w", ctx.Err())
+	}
+}
+
+// onThrottled slows down after a 429 response, pausing for retryAfter if the server
+// sent one.
+func (l *rateLimiter) onThrottled(retryAfter time.Duration) {
+	l.throttled.Add(1)
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(now)
+	l.rate = max(l.rate/2, l.baseRate*throttleMinRateFactor)
+	if l.tokens > 0 {
+		l.tokens = 0 // Drop the burst allowance: the server is already overwhelmed
+	}
+	if until := now.Add(retryAfter); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// onSuccess recovers part of the configured rate after a slowdown.
+func (l *rateLimiter) onSuccess() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate < l.baseRate {
+		l.advance(time.Now())
+		l.rate = min(l.rate+l.baseRate/throttleRecoverSteps, l.baseRate)
+	}
+}
+
+// concurrencyLimiter caps the number of requests in flight.
+type concurrencyLimiter struct {
+	slots chan struct{} // Buffered to the cap; holding an element holds a slot
+
+	waits     atomic.Int64
+	waitNanos atomic.Int64
+}
+
+// acquire blocks until a slot is free or ctx ends. Call release when done.
+func (s *concurrencyLimiter) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	s.waits.Add(1)
+	start := time.Now()
+	defer func() { s.waitNanos.Add(int64(time.Since(start))) }()
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for a concurrent request slot: %w", ctx.Err())
+	}
+}
+
+// release frees a slot taken by acquire.
+func (s *concurrencyLimiter) release() {
+	<-s.slots
+}
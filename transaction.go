@@ -0,0 +1,207 @@
+// transaction.go
+package nebula
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// TxRecordID identifies the record an Update, Delete or Get inside a transaction acts on:
+// either an existing record (RecordID) or one created earlier in the same transaction (*TxResult).
+type TxRecordID interface {
+	txRecordID()
+}
+
+// RecordID is the ID of an existing record, for use as a TxRecordID.
+type RecordID int64
+
+func (RecordID) txRecordID() {}
+
+// TxResult is the outcome of a single operation queued on a Tx. Its values are
+// populated once the transaction commits. A *TxResult from Create can also be used
+// before commit as a placeholder for the new record's ID: pass it as the TxRecordID
+// of a later operation, or as a value in later record data (e.g., a foreign key column).
+// Placeholders from other operations or other transactions are rejected.
+type TxResult struct {
+	index        int // Position of the operation in the batch
+	committed    bool
+	recordID     int64
+	rowsAffected int64
+	record       Record
+}
+
+func (*TxResult) txRecordID() {}
+
+// MarshalJSON encodes the result as a placeholder the server resolves to the record ID
+// produced by the referenced operation.
+func (r *TxResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]int{"$ref": r.index})
+}
+
+// ID returns the record ID affected by the operation (the new ID for Create).
+// It is 0 until the transaction has committed.
+func (r *TxResult) ID() int64 { return r.recordID }
+
+// RowsAffected returns the number of rows changed by an Update or Delete.
+// It is 0 until the transaction has committed.
+func (r *TxResult) RowsAffected() int64 { return r.rowsAffected }
+
+// Record returns the record read by Get, as seen at that point in the transaction.
+// It is nil until the transaction has committed.
+func (r *TxResult) Record() Record { return r.record }
+
+// Committed reports whether the transaction containing the operation has committed.
+func (r *TxResult) Committed() bool { return r.committed }
+
+// Tx collects record operations to be executed atomically by Client.Transaction.
+// Operations are queued locally and sent to the server as a single batch when the
+// transaction callback returns; nothing is executed before that.
+type Tx struct {
+	ops     []TxOperation
+	results []*TxResult
+	err     error // First validation error; reported by Transaction
+	done    bool
+}
+
+// Create queues the insertion of a record into tableName.
+// The returned *TxResult can be used as a placeholder for the new ID in later operations.
+func (tx *Tx) Create(tableName string, data map[string]interface{}) *TxResult {
+	if len(data) == 0 {
+		tx.fail(errors.New("record data cannot be empty"))
+	}
+	return tx.add("Tx.Create", TxOperation{Op: "create", Table: tableName, Data: data})
+}
+
+// Update queues a change to the fields in data of the record identified by id.
+func (tx *Tx) Update(tableName string, id TxRecordID, data map[string]interface{}) *TxResult {
+	if len(data) == 0 {
+		tx.fail(errors.New("update data cannot be empty"))
+	}
+	return tx.add("Tx.Update", TxOperation{Op: "update", Table: tableName, ID: id, Data: data})
+}
+
+// Delete queues the removal of the record identified by id.
+func (tx *Tx) Delete(tableName string, id TxRecordID) *TxResult {
+	return tx.add("Tx.Delete", TxOperation{Op: "delete", Table: tableName, ID: id})
+}
+
+// Get queues a read of the record identified by id. The read sees the effects of
+// earlier operations in the same transaction; its value is available from
+// TxResult.Record after commit.
+func (tx *Tx) Get(tableName string, id TxRecordID) *TxResult {
+	return tx.add("Tx.Get", TxOperation{Op: "get", Table: tableName, ID: id})
+}
+
+// add validates and queues op, returning its result handle.
+func (tx *Tx) add(op string, txOp TxOperation) *TxResult {
+	result := &TxResult{index: len(tx.ops)}
+	if tx.done {
+		tx.fail(ErrTransactionDone)
+		return result
+	}
+
+	errs := validateTableName(txOp.Table)
+	if txOp.Data != nil {
+		errs = append(errs, validateRecordData(txOp.Data)...)
+		for _, col := range sortedKeys(txOp.Data) {
+			if ref, ok := txOp.Data[col].(*TxResult); ok && !tx.createdEarlier(ref) {
+				errs.add(col, FieldCodeInvalid, "placeholder does not refer to an earlier Create of this transaction")
+			}
+		}
+	}
+	if txOp.Op != "create" {
+		switch id := txOp.ID.(type) {
+		case nil:
+			errs.add("id", FieldCodeRequired, "record ID is required")
+		case RecordID:
+			if id <= 0 {
+				errs.add("id", FieldCodeInvalid, "record ID must be positive")
+			}
+		case *TxResult:
+			if !tx.createdEarlier(id) {
+				errs.add("id", FieldCodeInvalid, "placeholder does not refer to an earlier Create of this transaction")
+			}
+		}
+	}
+	if err := errs.err(op); err != nil {
+		tx.fail(fmt.Errorf("operation %d: %w", result.index, err))
+	}
+
+	tx.ops = append(tx.ops, txOp)
+	tx.results = append(tx.results, result)
+	return result
+}
+
+// createdEarlier reports whether ref is the result of a Create already queued on tx,
+// so the server can resolve it to the new record's ID.
+func (tx *Tx) createdEarlier(ref *TxResult) bool {
+	return ref != nil && ref.index < len(tx.ops) && tx.results[ref.index] == ref && tx.ops[ref.index].Op == "create"
+}
+
+// fail records the first error encountered while building the transaction.
+func (tx *Tx) fail(err error) {
+	if tx.err == nil {
+		tx.err = err
+	}
+}
+
+// Transaction runs fn to build a batch of record operations on dbName and sends them
+// to the server as a single atomic unit: either every operation commits or none does.
+//
+// If fn returns an error, or any queued operation is invalid, nothing is sent and that
+// error is returned. Results (IDs, rows affected, records read) are available from the
+// *TxResult handles once Transaction returns nil. Servers without the transactions
+// capability fail with ErrUnsupportedByServer.
+//
+//	err := client.Transaction(ctx, "shop", func(tx *nebula.Tx) error {
+//		order := tx.Create("orders", map[string]interface{}{"customer": "ada"})
+//		tx.Create("order_items", map[string]interface{}{"order_id": order, "sku": "W-1", "qty": 2})
+//		return nil
+//	})
+func (c *Client) Transaction(ctx context.Context, dbName string, fn func(tx *Tx) error) error {
+	if fn == nil {
+		return errors.New("transaction callback cannot be nil")
+	}
+	apiPath, err := c.databasePath("Client.Transaction", dbName, "transaction")
+	if err != nil {
+		return err
+	}
+	if err := c.requireCapability("Client.Transaction", CapabilityTransactions); err != nil {
+		return err
+	}
+
+	tx := &Tx{}
+	fnErr := fn(tx)
+	tx.done = true
+	if fnErr != nil {
+		return fnErr // Rolled back: nothing was sent
+	}
+	if tx.err != nil {
+		return tx.err
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	var result TransactionResponse
+	err = c.doRequest(ctx, "Client.Transaction", http.MethodPost, apiPath, TransactionPayload{Operations: tx.ops}, &result)
+	if err != nil {
+		// Handles 400 (invalid op), 401, 404 (db/table/record not found), 409 (constraint), 500.
+		// The server rolls back every operation on failure. Older servers have no endpoint.
+		return unsupportedByServer("Client.Transaction", CapabilityTransactions, err)
+	}
+	if len(result.Results) != len(tx.ops) {
+		return fmt.Errorf("%w: transaction returned %d results for %d operations", ErrInvalidResponse, len(result.Results), len(tx.ops))
+	}
+
+	for i, r := range result.Results {
+		tx.results[i].recordID = r.RecordID
+		tx.results[i].rowsAffected = r.RowsAffected
+		tx.results[i].record = r.Record
+		tx.results[i].committed = true
+	}
+	return nil
+}
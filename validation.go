@@ -0,0 +1,214 @@
+// validation.go
+package nebula
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a problem with a single field of a request: a column of a
+// record payload, a column of a schema definition, or a filter key.
+// The same type is produced by client-side validation and parsed from the
+// "details" array of structured API error responses.
+type FieldError struct {
+	Field   string `json:"field"`          // Column, filter key or payload path (e.g., "columns[1].type")
+	Code    string `json:"code,omitempty"` // Machine-readable reason (e.g., "required", "invalid_type")
+	Message string `json:"message"`        // Human-readable description
+}
+
+// Error implements the error interface for FieldError.
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Field error codes used by client-side validation. The server may send others.
+const (
+	FieldCodeRequired    = "required"         // A required value is missing or empty
+	FieldCodeInvalid     = "invalid"          // The value is malformed (e.g., bad identifier)
+	FieldCodeInvalidType = "invalid_type"     // The value's type doesn't match the column
+	FieldCodeDuplicate   = "duplicate"        // The name appears more than once
+	FieldCodeUnsupported = "unsupported_type" // The value or column type isn't supported
+)
+
+// ValidationError is returned when client-side validation rejects a request
+// before it is sent. It matches ErrValidation via errors.Is.
+type ValidationError struct {
+	Operation string       // The SDK operation that was rejected (e.g., "Records.Create")
+	Details   []FieldError // One entry per invalid field
+}
+
+// Error implements the error interface for ValidationError.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Details))
+	for i, d := range e.Details {
+		msgs[i] = d.Error()
+	}
+	prefix := "validation failed"
+	if e.Operation != "" {
+		prefix = e.Operation + ": " + prefix
+	}
+	return prefix + ": " + strings.Join(msgs, "; ")
+}
+
+// Unwrap allows errors.Is(err, ErrValidation).
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// FieldErrors returns the per-field details carried by err, whether it came from
+// client-side validation (*ValidationError) or from a structured API error (*APIError).
+// It returns nil if err has no field-level details.
+func FieldErrors(err error) []FieldError {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		return vErr.Details
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Details
+	}
+	return nil
+}
+
+// fieldErrors accumulates FieldErrors during validation.
+type fieldErrors []FieldError
+
+// add records a field error.
+func (f *fieldErrors) add(field, code, format string, args ...interface{}) {
+	*f = append(*f, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *ValidationError for op if any field errors were recorded, or nil.
+func (f fieldErrors) err(op string) error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Operation: op, Details: f}
+}
+
+// identifierPattern matches column names accepted by the Nebula backend.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// columnTypes lists the column types accepted by DefineSchema.
+var columnTypes = map[string]bool{
+	"TEXT":    true,
+	"INTEGER": true,
+	"REAL":    true,
+	"BLOB":    true,
+	"BOOLEAN": true,
+}
+
+// reservedFilterKeys are query parameters the records endpoints read themselves. A filter
+// on one would be taken as that option (e.g., all_rows=true bypassing the AllRows guard).
+var reservedFilterKeys = map[string]bool{
+	"all_rows": true,
+	"limit":    true,
+	"offset":   true,
+	"sort":     true,
+}
+
+// validateSchema checks a schema definition for missing or malformed table and column names,
+// unsupported column types and duplicate columns.
+func validateSchema(schema SchemaPayload) fieldErrors {
+	errs := validateTableName(schema.TableName)
+	if len(schema.Columns) == 0 {
+		errs.add("columns", FieldCodeRequired, "schema must contain at least one column")
+	}
+
+	seen := make(map[string]bool, len(schema.Columns))
+	for i, col := range schema.Columns {
+		field := fmt.Sprintf("columns[%d]", i)
+		switch {
+		case col.Name == "":
+			errs.add(field+".name", FieldCodeRequired, "column name cannot be empty")
+		case !identifierPattern.MatchString(col.Name):
+			errs.add(field+".name", FieldCodeInvalid, "column name %q must start with a letter or underscore and contain only letters, digits and underscores", col.Name)
+		case seen[strings.ToLower(col.Name)]:
+			errs.add(field+".name", FieldCodeDuplicate, "column %q is defined more than once", col.Name)
+		}
+		seen[strings.ToLower(col.Name)] = true
+
+		if !columnTypes[strings.ToUpper(col.Type)] {
+			errs.add(field+".type", FieldCodeUnsupported, "unsupported column type %q (expected TEXT, INTEGER, REAL, BLOB or BOOLEAN)", col.Type)
+		}
+	}
+	return errs
+}
+
+// validateFilters checks that every filter key is a valid column name and not a
+// reserved query parameter. Empty keys are ignored, matching how they are skipped when
+// building the query.
+func validateFilters(filters map[string]string) fieldErrors {
+	var errs fieldErrors
+	for _, key := range sortedKeys(filters) {
+		switch {
+		case key == "":
+		case !identifierPattern.MatchString(key):
+			errs.add(key, FieldCodeInvalid, "filter key %q is not a valid column name", key)
+		case reservedFilterKeys[key]:
+			errs.add(key, FieldCodeInvalid, "filter key %q is a reserved query parameter and can't be filtered on", key)
+		}
+	}
+	return errs
+}
+
+// validateRecordData checks that every key of a record payload is a valid column name
+// and every value is a JSON scalar the backend can store (string, number, bool, nil or []byte).
+func validateRecordData(data map[string]interface{}) fieldErrors {
+	var errs fieldErrors
+	for _, key := range sortedKeys(data) {
+		value := data[key]
+		if !identifierPattern.MatchString(key) {
+			errs.add(key, FieldCodeInvalid, "column name %q is not valid", key)
+			continue
+		}
+		if !isScalarValue(value) {
+			errs.add(key, FieldCodeInvalidType, "unsupported value of type %T (expected string, number, bool, []byte or nil)", value)
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of m in sorted order, for deterministic error output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isScalarValue reports whether v can be stored in a single column.
+func isScalarValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch v.(type) {
+	case []byte, json.Marshaler: // Self-encoding values (e.g., time.Time, *TxResult placeholders)
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// version.go
+package nebula
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const (
+	modifyMaxAttempts = 5                     // Read-modify-write attempts before Modify gives up
+	modifyBaseBackoff = 20 * time.Millisecond // Initial delay between Modify attempts (doubles each retry)
+)
+
+// GetWithVersion retrieves a single record like Get and also returns its version token
+// (the ETag response header). Pass the version to Update or Delete via IfMatch to make
+// them fail with ErrPreconditionFailed if another writer changed the record in between.
+// Returns ErrVersionUnavailable if the server doesn't provide a version.
+func (s *RecordService) GetWithVersion(ctx context.Context, dbName, tableName string, recordID int64, reqOpts ...RequestOption) (Record, string, error) {
+	var header http.Header
+	record, err := s.Get(ctx, dbName, tableName, recordID, append(reqOpts, captureResponseHeader(&header))...)
+	if err != nil {
+		return nil, "", err
+	}
+	version := header.Get("ETag")
+	if version == "" {
+		return record, "", ErrVersionUnavailable
+	}
+	return record, version, nil
+}
+
+// Modify performs an optimistic read-modify-write of a single record. It fetches the
+// record with its version, calls fn to mutate it in place, and sends the changed fields
+// as a conditional Update (If-Match). If another writer changed the record in the
+// meantime, the whole cycle is retried with the fresh record, up to 5 attempts.
+//
+// Returning an error from fn aborts without writing. If fn changes nothing, no update is
+// sent. Modify returns the record as last written (or read, if unchanged). It fails with
+// ErrVersionUnavailable, before calling fn, if the server sends no version (ETag) for the
+// record, since the update couldn't be made conditional.
+//
+//	rec, err := client.Records.Modify(ctx, "shop", "stock", id, func(rec nebula.Record) error {
+//		qty, err := rec.Int64("quantity")
+//		if err != nil {
+//			return err
+//		}
+//		rec["quantity"] = qty - 1
+//		return nil
+//	})
+func (s *RecordService) Modify(ctx context.Context, dbName, tableName string, recordID int64, fn func(rec Record) error) (Record, error) {
+	if fn == nil {
+		return nil, errors.New("modify callback cannot be nil")
+	}
+
+	backoff := modifyBaseBackoff
+	for attempt := 1; ; attempt++ {
+		current, version, err := s.GetWithVersion(ctx, dbName, tableName, recordID)
+		if err != nil {
+			return nil, err
+		}
+
+		original := make(Record, len(current))
+		for k, v := range current {
+			original[k] = v
+		}
+		if err := fn(current); err != nil {
+			return nil, err
+		}
+
+		patch := recordPatch(original, current)
+		if len(patch) == 0 {
+			return current, nil // Nothing changed; nothing to write
+		}
+
+		err = s.Update(ctx, dbName, tableName, recordID, patch, IfMatch(version))
+		if err == nil {
+			return current, nil
+		}
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return nil, err
+		}
+		if attempt >= modifyMaxAttempts {
+			return nil, fmt.Errorf("record %d still conflicting after %d attempts: %w", recordID, attempt, err)
+		}
+
+		// Another writer won the race; wait a little (with jitter) and start over
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+// recordPatch returns the fields of updated that differ from original.
+// Fields removed by the callback are set to nil (NULL). The "id" column is never patched.
+func recordPatch(original, updated Record) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for k, v := range updated {
+		if k == "id" {
+			continue
+		}
+		if old, ok := original[k]; !ok || !reflect.DeepEqual(old, v) {
+			patch[k] = v
+		}
+	}
+	for k := range original {
+		if _, ok := updated[k]; !ok && k != "id" {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
@@ -0,0 +1,486 @@
+// watch.go
+package nebula
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWatchHeartbeatTimeout  = 45 * time.Second // Reconnect if the stream is silent this long
+	defaultWatchReconnectDelay    = 1 * time.Second  // First reconnect delay (doubles up to the max)
+	defaultWatchMaxReconnectDelay = 30 * time.Second
+	defaultWatchPollInterval      = 5 * time.Second // List interval in polling fallback mode
+	watchEventBuffer              = 64              // Events buffered before Watch blocks on the consumer
+)
+
+// ChangeType is the kind of change reported by a ChangeEvent.
+type ChangeType string
+
+// Change types reported by RecordService.Watch.
+const (
+	ChangeInsert ChangeType = "insert"
+	ChangeUpdate ChangeType = "update"
+	ChangeDelete ChangeType = "delete"
+)
+
+// ChangeEvent describes a single change to a record in a watched table.
+type ChangeEvent struct {
+	Type     ChangeType `json:"type"`
+	Sequence int64      `json:"seq"`       // Monotonic position in the table's change feed
+	RecordID int64      `json:"record_id"` // ID of the changed record
+	Record   Record     `json:"record"`    // Record after the change (before it, for deletes, if the server sends it)
+}
+
+// WatchOptions specifies optional parameters for RecordService.Watch.
+// Zero values select the defaults noted on each field.
+type WatchOptions struct {
+	// Since resumes the feed after this sequence number (0 = only new changes).
+	Since int64
+
+	// HeartbeatTimeout is how long the stream may stay silent (no events or heartbeats)
+	// before the connection is considered dead and re-established. Default 45s.
+	HeartbeatTimeout time.Duration
+
+	// ReconnectDelay is the initial delay before reconnecting after a dropped stream,
+	// doubling on each consecutive failure up to MaxReconnectDelay. Defaults 1s and 30s.
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+
+	// PollInterval is how often the table is listed when the server has no streaming
+	// endpoint and Watch falls back to diffing List results. Default 5s.
+	PollInterval time.Duration
+
+	// DisableStreaming forces the polling fallback.
+	DisableStreaming bool
+}
+
+// Watcher delivers change events for a table. Create one with RecordService.Watch.
+type Watcher struct {
+	events chan ChangeEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	lastSeq int64
+	polling bool
+}
+
+// Events returns the channel of change events. It is closed when the watcher stops,
+// after which Err reports why.
+func (w *Watcher) Events() <-chan ChangeEvent {
+	return w.events
+}
+
+// Err returns the error that stopped the watcher, or nil if it is still running or was
+// stopped by Close or context cancellation.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// LastSequence returns the sequence number of the last delivered event. Pass it as
+// WatchOptions.Since to resume a later Watch where this one left off.
+func (w *Watcher) LastSequence() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastSeq
+}
+
+// Polling reports whether the watcher fell back to polling because the server has no
+// streaming endpoint.
+func (w *Watcher) Polling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.polling
+}
+
+// Close stops the watcher and waits for its goroutine to exit.
+func (w *Watcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+// Watch subscribes to changes (inserts, updates, deletes) of records in a table that
+// match filter, using the server's Server-Sent Events feed. Dropped or silent connections
+// are re-established automatically, resuming after the last received sequence number.
+// If the server has no streaming endpoint, Watch falls back to polling List and diffing
+// the results (sequence numbers are then assigned locally).
+//
+// The watcher runs until ctx is cancelled, Close is called, or a non-retryable error
+// occurs (see Watcher.Err).
+//
+//	w, err := client.Records.Watch(ctx, "jobs", "runs", nebula.Filter{"status": "failed"}, nil)
+//	if err != nil {
+//		return err
+//	}
+//	for ev := range w.Events() {
+//		log.Printf("%s record %d", ev.Type, ev.RecordID)
+//	}
+//	return w.Err()
+func (s *RecordService) Watch(ctx context.Context, dbName, tableName string, filter Filter, opts *WatchOptions) (*Watcher, error) {
+	apiPath, err := s.buildRecordPath("Records.Watch", dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFilters(filter).err("Records.Watch"); err != nil {
+		return nil, err
+	}
+
+	o := WatchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.HeartbeatTimeout <= 0 {
+		o.HeartbeatTimeout = defaultWatchHeartbeatTimeout
+	}
+	if o.ReconnectDelay <= 0 {
+		o.ReconnectDelay = defaultWatchReconnectDelay
+	}
+	if o.MaxReconnectDelay < o.ReconnectDelay {
+		o.MaxReconnectDelay = defaultWatchMaxReconnectDelay
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultWatchPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watcher{
+		events:  make(chan ChangeEvent, watchEventBuffer),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		lastSeq: o.Since,
+	}
+	wr := &watchRun{svc: s, w: w, opts: o, basePath: apiPath, dbName: dbName, tableName: tableName, filter: filter}
+
+	go func() {
+		defer close(w.done)
+		defer close(w.events)
+		err := wr.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+		}
+	}()
+	return w, nil
+}
+
+// watchRun holds the state of a running Watch.
+type watchRun struct {
+	svc                 *RecordService
+	w                   *Watcher
+	opts                WatchOptions
+	basePath            string
+	dbName, tableName   string
+	filter              Filter
+	serverRetryOverride time.Duration // Reconnect delay requested by the server via "retry:"
+}
+
+// run streams until a terminal error, switching to polling if streaming is unsupported
+// (or known from ServerInfo to be unsupported).
+func (r *watchRun) run(ctx context.Context) error {
+	if !r.opts.DisableStreaming && !r.svc.client.lacksCapability(CapabilityWatch) {
+		err := r.stream(ctx)
+		if !errors.Is(err, errWatchUnsupported) {
+			return err
+		}
+	}
+	r.w.mu.Lock()
+	r.w.polling = true
+	r.w.mu.Unlock()
+	return r.poll(ctx)
+}
+
+// errWatchUnsupported signals that the server has no streaming endpoint.
+var errWatchUnsupported = errors.New("server does not support record change streams")
+
+// stream maintains the SSE connection, reconnecting with backoff on retryable failures.
+func (r *watchRun) stream(ctx context.Context) error {
+	delay := r.opts.ReconnectDelay
+	connectedOnce := false
+	for {
+		received, err := r.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !connectedOnce && !received && endpointUnsupported(err) {
+			return errWatchUnsupported
+		}
+		if err != nil && !IsRetryable(err) && !errors.Is(err, errWatchStalled) {
+			return err
+		}
+		if received {
+			connectedOnce = true
+			delay = r.opts.ReconnectDelay // Healthy connection; reset backoff
+		}
+
+		wait := delay
+		if r.serverRetryOverride > 0 {
+			wait = r.serverRetryOverride
+		}
+		if ra, ok := RetryAfter(err); ok && ra > wait {
+			wait = ra
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+		if delay > r.opts.MaxReconnectDelay {
+			delay = r.opts.MaxReconnectDelay
+		}
+	}
+}
+
+// errWatchStalled is reported when no data arrived within the heartbeat timeout.
+var errWatchStalled = errors.New("change stream heartbeat timeout")
+
+// connect opens one SSE connection and delivers its events until it ends.
+// received reports whether the connection was established successfully.
+func (r *watchRun) connect(ctx context.Context) (received bool, err error) {
+	queryValues := url.Values{}
+	if err := addFilterQuery("Records.Watch", queryValues, r.filter); err != nil {
+		return false, err
+	}
+	lastSeq := r.w.LastSequence()
+	if lastSeq > 0 {
+		queryValues.Set("since", strconv.FormatInt(lastSeq, 10))
+	}
+	apiPath := r.basePath + "/watch"
+	if len(queryValues) > 0 {
+		apiPath += "?" + queryValues.Encode()
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(r.opts.HeartbeatTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	reqOpts := []RequestOption{longLivedRequest(), MaxResponseBytes(0), withHeader("Accept", "text/event-stream")}
+	if lastSeq > 0 {
+		reqOpts = append(reqOpts, withHeader("Last-Event-ID", strconv.FormatInt(lastSeq, 10)))
+	}
+	resp, err := r.svc.client.send(connCtx, "Records.Watch", http.MethodGet, apiPath, nil, r.svc.client.requestOptions(reqOpts))
+	if err != nil {
+		if stalled.Load() {
+			return false, errWatchStalled // No response headers within the heartbeat timeout
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		// A server without streaming may answer the path with a plain JSON response
+		return false, &APIError{StatusCode: http.StatusNotAcceptable, Message: "unexpected content type " + ct, Operation: "Records.Watch"}
+	}
+
+	var sse sseEvent
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			watchdog.Reset(r.opts.HeartbeatTimeout) // Any data, including heartbeats, proves liveness
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" && readErr == nil {
+			if err := r.dispatch(ctx, sse); err != nil {
+				return true, err
+			}
+			sse = sseEvent{}
+			continue
+		}
+		if line != "" {
+			r.parseLine(&sse, line)
+		}
+
+		if readErr != nil {
+			if stalled.Load() {
+				return true, errWatchStalled
+			}
+			if ctx.Err() != nil {
+				return true, ctx.Err()
+			}
+			return true, &transportError{method: http.MethodGet, err: readErr} // EOF or broken stream; reconnect
+		}
+	}
+}
+
+// sseEvent accumulates the fields of one Server-Sent Event.
+type sseEvent struct {
+	id    string
+	event string
+	data  strings.Builder
+}
+
+// parseLine applies a single SSE line to ev.
+func (r *watchRun) parseLine(ev *sseEvent, line string) {
+	if strings.HasPrefix(line, ":") {
+		return // Comment; servers use these as heartbeats
+	}
+	field, value, _ := strings.Cut(line, ":")
+	value = strings.TrimPrefix(value, " ")
+	switch field {
+	case "id":
+		ev.id = value
+	case "event":
+		ev.event = value
+	case "data":
+		if ev.data.Len() > 0 {
+			ev.data.WriteByte('\n')
+		}
+		ev.data.WriteString(value)
+	case "retry":
+		if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+			r.serverRetryOverride = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+// dispatch decodes a complete SSE event and delivers it if it is a change event.
+func (r *watchRun) dispatch(ctx context.Context, ev sseEvent) error {
+	switch ChangeType(ev.event) {
+	case ChangeInsert, ChangeUpdate, ChangeDelete:
+	default:
+		return nil // Heartbeats ("heartbeat", "ping") and unknown event types
+	}
+
+	var change ChangeEvent
+	if ev.data.Len() > 0 {
+		if err := json.Unmarshal([]byte(ev.data.String()), &change); err != nil {
+			return fmt.Errorf("%w: malformed change event: %w", ErrInvalidResponse, err)
+		}
+	}
+	change.Type = ChangeType(ev.event)
+	if change.Sequence == 0 && ev.id != "" {
+		change.Sequence, _ = strconv.ParseInt(ev.id, 10, 64)
+	}
+	if change.RecordID == 0 && change.Record != nil {
+		change.RecordID, _ = change.Record.Int64("id")
+	}
+	if change.Sequence > 0 && change.Sequence <= r.w.LastSequence() {
+		return nil // Already delivered before a reconnect
+	}
+	return r.deliver(ctx, change)
+}
+
+// deliver sends change to the consumer and records its sequence number.
+func (r *watchRun) deliver(ctx context.Context, change ChangeEvent) error {
+	select {
+	case r.w.events <- change:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.w.mu.Lock()
+	if change.Sequence > r.w.lastSeq {
+		r.w.lastSeq = change.Sequence
+	}
+	r.w.mu.Unlock()
+	return nil
+}
+
+// poll lists the table every PollInterval and emits events for the differences
+// between consecutive snapshots. The first snapshot establishes the baseline.
+func (r *watchRun) poll(ctx context.Context) error {
+	var previous map[int64]Record
+	seq := r.w.LastSequence()
+	delay := r.opts.ReconnectDelay
+
+	for {
+		current, err := r.snapshot(ctx)
+		switch {
+		case err == nil:
+			delay = r.opts.ReconnectDelay
+			if previous != nil {
+				for _, change := range diffSnapshots(previous, current) {
+					seq++
+					change.Sequence = seq
+					if err := r.deliver(ctx, change); err != nil {
+						return err
+					}
+				}
+			}
+			previous = current
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !IsRetryable(err):
+			return err
+		}
+
+		wait := r.opts.PollInterval
+		if err != nil {
+			wait = delay // Back off on transient failures
+			delay *= 2
+			if delay > r.opts.MaxReconnectDelay {
+				delay = r.opts.MaxReconnectDelay
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// snapshot lists every matching record keyed by ID.
+func (r *watchRun) snapshot(ctx context.Context) (map[int64]Record, error) {
+	records := make(map[int64]Record)
+	err := r.svc.Stream(ctx, r.dbName, r.tableName, &ListRecordsOptions{Filters: r.filter}, func(rec Record) error {
+		id, err := rec.Int64("id")
+		if err != nil {
+			return fmt.Errorf("%w: record without numeric id: %w", ErrInvalidResponse, err)
+		}
+		records[id] = rec
+		return nil
+	})
+	return records, err
+}
+
+// diffSnapshots returns insert/update/delete events turning previous into current,
+// ordered by record ID for deterministic output.
+func diffSnapshots(previous, current map[int64]Record) []ChangeEvent {
+	var changes []ChangeEvent
+	for id, rec := range current {
+		old, ok := previous[id]
+		switch {
+		case !ok:
+			changes = append(changes, ChangeEvent{Type: ChangeInsert, RecordID: id, Record: rec})
+		case !reflect.DeepEqual(old, rec):
+			changes = append(changes, ChangeEvent{Type: ChangeUpdate, RecordID: id, Record: rec})
+		}
+	}
+	for id, rec := range previous {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, ChangeEvent{Type: ChangeDelete, RecordID: id, Record: rec})
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// sortChanges orders changes by record ID.
+func sortChanges(changes []ChangeEvent) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].RecordID < changes[j].RecordID
+	})
+}
@@ -0,0 +1,97 @@
+// event.go
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	nebula "github.com/Annany2002/nebula-sdk-go"
+)
+
+// EventType identifies the kind of change a webhook notifies about.
+type EventType string
+
+// Event types sent by the Nebula backend.
+const (
+	RecordCreated   EventType = "record.created"
+	RecordUpdated   EventType = "record.updated"
+	RecordDeleted   EventType = "record.deleted"
+	TableCreated    EventType = "table.created"
+	TableDeleted    EventType = "table.deleted"
+	DatabaseCreated EventType = "database.created"
+	DatabaseDeleted EventType = "database.deleted"
+)
+
+// Event is the envelope common to every webhook notification.
+type Event struct {
+	ID        string          `json:"id"`         // Unique event ID; used for replay detection
+	Type      EventType       `json:"type"`       // e.g., "record.created"
+	CreatedAt time.Time       `json:"created_at"` // When the change happened on the server
+	Database  string          `json:"database"`   // Database the change belongs to
+	Table     string          `json:"table,omitempty"`
+	Data      json.RawMessage `json:"data"` // Type-specific payload; see RecordEvent, TableEvent, DatabaseEvent
+}
+
+// RecordEvent is a record.created, record.updated or record.deleted notification.
+type RecordEvent struct {
+	Event
+	RecordID int64         // ID of the affected record
+	Record   nebula.Record // Record after the change (the deleted record for record.deleted)
+	Previous nebula.Record // Record before the change, for record.updated when the server includes it
+}
+
+// TableEvent is a table.created or table.deleted notification.
+type TableEvent struct {
+	Event
+	Columns []nebula.ColumnDefinition // Schema of the created table; empty for table.deleted
+}
+
+// DatabaseEvent is a database.created or database.deleted notification.
+type DatabaseEvent struct {
+	Event
+}
+
+// recordData is the "data" payload of record events.
+type recordData struct {
+	RecordID int64         `json:"record_id"`
+	Record   nebula.Record `json:"record"`
+	Previous nebula.Record `json:"previous,omitempty"`
+}
+
+// tableData is the "data" payload of table events.
+type tableData struct {
+	Columns []nebula.ColumnDefinition `json:"columns"`
+}
+
+// decodeRecordEvent decodes the data payload of a record event.
+func decodeRecordEvent(ev Event) (*RecordEvent, error) {
+	var data recordData
+	if err := unmarshalData(ev, &data); err != nil {
+		return nil, err
+	}
+	if data.RecordID == 0 && data.Record != nil {
+		data.RecordID, _ = data.Record.Int64("id")
+	}
+	return &RecordEvent{Event: ev, RecordID: data.RecordID, Record: data.Record, Previous: data.Previous}, nil
+}
+
+// decodeTableEvent decodes the data payload of a table event.
+func decodeTableEvent(ev Event) (*TableEvent, error) {
+	var data tableData
+	if err := unmarshalData(ev, &data); err != nil {
+		return nil, err
+	}
+	return &TableEvent{Event: ev, Columns: data.Columns}, nil
+}
+
+// unmarshalData decodes ev.Data into v, tolerating an absent payload.
+func unmarshalData(ev Event, v interface{}) error {
+	if len(ev.Data) == 0 || string(ev.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(ev.Data, v); err != nil {
+		return fmt.Errorf("webhook: malformed %s payload: %w", ev.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// handler.go
+
+// Package webhook receives Nebula change notifications: it verifies their HMAC signatures
+// and timestamps, decodes them into typed events and dispatches them to registered handlers.
+//
+//	h := webhook.NewHandler([]byte(os.Getenv("NEBULA_WEBHOOK_SECRET")))
+//	h.OnRecord(webhook.RecordCreated, func(ctx context.Context, ev *webhook.RecordEvent) error {
+//		log.Printf("new record %d in %s.%s", ev.RecordID, ev.Database, ev.Table)
+//		return nil
+//	})
+//	http.Handle("/hooks/nebula", h)
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultMaxBodyBytes = 1 * 1024 * 1024 // Limit webhook body size to 1MB for safety
+
+// Handler is an http.Handler that verifies, decodes and dispatches webhook events.
+// Register callbacks with OnRecord, OnTable, OnDatabase and OnAny before serving.
+// A callback error makes the handler respond 500 so the sender retries the delivery.
+// A redelivery of an event that was handled is acknowledged with 200 without calling
+// the callbacks again; one arriving while the first delivery is still being handled
+// gets 409, so the sender retries it later in case the first fails.
+type Handler struct {
+	secret       []byte
+	tolerance    time.Duration
+	maxBodyBytes int64
+	now          func() time.Time
+
+	recordHandlers   map[EventType]func(context.Context, *RecordEvent) error
+	tableHandlers    map[EventType]func(context.Context, *TableEvent) error
+	databaseHandlers map[EventType]func(context.Context, *DatabaseEvent) error
+	anyHandler       func(context.Context, *Event) error
+
+	mu   sync.Mutex
+	seen map[string]*delivery // Event IDs received within the replay window
+}
+
+// delivery is the replay state of an event ID.
+type delivery struct {
+	at   time.Time // When the event was first received
+	done bool      // Handled successfully; false while its handler runs
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithTolerance sets the replay window: the maximum allowed difference between a
+// signature's timestamp and the local clock. Defaults to DefaultTolerance.
+func WithTolerance(d time.Duration) Option {
+	return func(h *Handler) {
+		if d > 0 {
+			h.tolerance = d
+		}
+	}
+}
+
+// WithMaxBodyBytes limits the size of accepted webhook bodies. Defaults to 1MB.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodyBytes = n
+		}
+	}
+}
+
+// WithClock overrides the time source used for timestamp checks (useful in tests).
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) {
+		if now != nil {
+			h.now = now
+		}
+	}
+}
+
+// NewHandler creates a Handler verifying signatures with secret.
+func NewHandler(secret []byte, opts ...Option) *Handler {
+	h := &Handler{
+		secret:           secret,
+		tolerance:        DefaultTolerance,
+		maxBodyBytes:     defaultMaxBodyBytes,
+		now:              time.Now,
+		recordHandlers:   make(map[EventType]func(context.Context, *RecordEvent) error),
+		tableHandlers:    make(map[EventType]func(context.Context, *TableEvent) error),
+		databaseHandlers: make(map[EventType]func(context.Context, *DatabaseEvent) error),
+		seen:             make(map[string]*delivery),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// OnRecord registers fn for a record event type (RecordCreated, RecordUpdated, RecordDeleted).
+func (h *Handler) OnRecord(t EventType, fn func(context.Context, *RecordEvent) error) {
+	h.recordHandlers[t] = fn
+}
+
+// OnTable registers fn for a table event type (TableCreated, TableDeleted).
+func (h *Handler) OnTable(t EventType, fn func(context.Context, *TableEvent) error) {
+	h.tableHandlers[t] = fn
+}
+
+// OnDatabase registers fn for a database event type (DatabaseCreated, DatabaseDeleted).
+func (h *Handler) OnDatabase(t EventType, fn func(context.Context, *DatabaseEvent) error) {
+	h.databaseHandlers[t] = fn
+}
+
+// OnAny registers fn for events with no type-specific handler, including event types
+// this package doesn't know yet. Without it, such events are acknowledged and ignored.
+func (h *Handler) OnAny(fn func(context.Context, *Event) error) {
+	h.anyHandler = fn
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.maxBodyBytes+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.maxBodyBytes {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ev, err := h.Parse(r.Header.Get(SignatureHeader), body)
+	switch {
+	case errors.Is(err, ErrReplayed):
+		w.WriteHeader(http.StatusOK) // Already processed; acknowledge so the sender stops retrying
+		return
+	case errors.Is(err, ErrInProgress):
+		http.Error(w, err.Error(), http.StatusConflict) // Not yet acknowledged: the sender must retry
+		return
+	case errors.Is(err, ErrMissingSignature), errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrTimestampOutOfRange):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Dispatch(r.Context(), ev); err != nil {
+		log.Printf("Webhook Error: handler for %s event %s failed: %v", ev.Type, ev.ID, err)
+		http.Error(w, "event handler failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Parse verifies the signature header and decodes body into an Event.
+// Events whose ID was already handled within the replay window yield ErrReplayed, and
+// those whose earlier delivery is still being handled ErrInProgress. A returned event
+// counts as in progress until it is passed to Dispatch.
+func (h *Handler) Parse(signature string, body []byte) (*Event, error) {
+	now := h.now()
+	if err := Verify(h.secret, signature, body, h.tolerance, now); err != nil {
+		return nil, err
+	}
+
+	var ev Event
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(&ev); err != nil {
+		return nil, fmt.Errorf("webhook: malformed event: %w", err)
+	}
+	if ev.Type == "" {
+		return nil, errors.New("webhook: event has no type")
+	}
+	if ev.ID != "" {
+		if err := h.remember(ev.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	return &ev, nil
+}
+
+// Dispatch decodes ev into its typed form and calls the registered handler. On success
+// the event is marked as handled; on failure it is forgotten, so a redelivery is
+// processed again.
+func (h *Handler) Dispatch(ctx context.Context, ev *Event) error {
+	err := h.dispatch(ctx, ev)
+	if ev.ID != "" {
+		h.finish(ev.ID, err == nil)
+	}
+	return err
+}
+
+// dispatch calls the handler registered for ev's type.
+func (h *Handler) dispatch(ctx context.Context, ev *Event) error {
+	if fn, ok := h.recordHandlers[ev.Type]; ok {
+		recEv, err := decodeRecordEvent(*ev)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, recEv)
+	}
+	if fn, ok := h.tableHandlers[ev.Type]; ok {
+		tblEv, err := decodeTableEvent(*ev)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, tblEv)
+	}
+	if fn, ok := h.databaseHandlers[ev.Type]; ok {
+		return fn(ctx, &DatabaseEvent{Event: *ev})
+	}
+	if h.anyHandler != nil {
+		return h.anyHandler(ctx, ev)
+	}
+	return nil // No handler registered; acknowledge and ignore
+}
+
+// remember records an event ID as in progress. It returns ErrReplayed if the ID was
+// already handled within the replay window and ErrInProgress if it is being handled.
+// Entries older than twice the tolerance are pruned.
+func (h *Handler) remember(id string, now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for seenID, d := range h.seen {
+		if now.Sub(d.at) > 2*h.tolerance {
+			delete(h.seen, seenID)
+		}
+	}
+	if d, ok := h.seen[id]; ok {
+		if d.done {
+			return ErrReplayed
+		}
+		return ErrInProgress
+	}
+	h.seen[id] = &delivery{at: now}
+	return nil
+}
+
+// finish marks an event ID as handled, or forgets it so a redelivery is processed again.
+func (h *Handler) finish(id string, handled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !handled {
+		delete(h.seen, id)
+	} else if d, ok := h.seen[id]; ok {
+		d.done = true
+	}
+}
+
+// NewSignedRequest builds a POST request to url carrying payload with a valid signature
+// for secret, timestamped now. It is intended for testing webhook receivers.
+func NewSignedRequest(url string, secret []byte, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(secret, payload, time.Now()))
+	return req, nil
+}
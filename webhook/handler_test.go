@@ -0,0 +1,186 @@
+// handler_test.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret = []byte("s3cret")
+	testNow    = time.Unix(1_700_000_000, 0)
+)
+
+// deliver sends payload to h signed at signedAt and returns the response status.
+func deliver(h http.Handler, payload string, signedAt time.Time) int {
+	req := httptest.NewRequest(http.MethodPost, "/hooks/nebula", bytes.NewBufferString(payload))
+	req.Header.Set(SignatureHeader, Sign(testSecret, []byte(payload), signedAt))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func recordCreated(id string) string {
+	return fmt.Sprintf(`{"id": %q, "type": "record.created", "database": "shop", "table": "orders", "data": {"record": {"id": 42, "total": 9.5}}}`, id)
+}
+
+func TestHandlerDispatch(t *testing.T) {
+	h := NewHandler(testSecret, WithClock(func() time.Time { return testNow }))
+	var got *RecordEvent
+	h.OnRecord(RecordCreated, func(ctx context.Context, ev *RecordEvent) error {
+		got = ev
+		return nil
+	})
+	var other *Event
+	h.OnAny(func(ctx context.Context, ev *Event) error {
+		other = ev
+		return nil
+	})
+
+	if code := deliver(h, recordCreated("evt_1"), testNow); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", code)
+	}
+	if got == nil || got.RecordID != 42 || got.Database != "shop" || got.Table != "orders" {
+		t.Fatalf("record event = %+v", got)
+	}
+	if total, err := got.Record.Float("total"); err != nil || total != 9.5 {
+		t.Errorf("record total = %v, %v", total, err)
+	}
+	if code := deliver(h, `{"id": "evt_2", "type": "index.rebuilt"}`, testNow); code != http.StatusNoContent || other == nil || other.Type != "index.rebuilt" {
+		t.Errorf("unknown event type: status %d, OnAny got %+v", code, other)
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	h := NewHandler(testSecret, WithClock(func() time.Time { return testNow }), WithTolerance(time.Minute), WithMaxBodyBytes(256))
+	var calls atomic.Int32
+	h.OnAny(func(ctx context.Context, ev *Event) error {
+		calls.Add(1)
+		return nil
+	})
+
+	payload := recordCreated("evt_1")
+	tampered := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(payload))
+	tampered.Header.Set(SignatureHeader, Sign([]byte("wrong"), []byte(payload), testNow))
+	unsigned := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(payload))
+	get := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := []struct {
+		name string
+		code int
+		want int
+	}{
+		{"stale signature", deliver(h, payload, testNow.Add(-2*time.Minute)), http.StatusUnauthorized},
+		{"future signature", deliver(h, payload, testNow.Add(2*time.Minute)), http.StatusUnauthorized},
+		{"malformed JSON", deliver(h, `{"id": `, testNow), http.StatusBadRequest},
+		{"no event type", deliver(h, `{"id": "evt_9"}`, testNow), http.StatusBadRequest},
+		{"body too large", deliver(h, fmt.Sprintf(`{"type": "x", "pad": %q}`, bytes.Repeat([]byte("a"), 300)), testNow), http.StatusRequestEntityTooLarge},
+	}
+	for _, req := range []struct {
+		name string
+		r    *http.Request
+		want int
+	}{
+		{"wrong secret", tampered, http.StatusUnauthorized},
+		{"no signature", unsigned, http.StatusUnauthorized},
+		{"GET", get, http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req.r)
+		tests = append(tests, struct {
+			name string
+			code int
+			want int
+		}{req.name, rec.Code, req.want})
+	}
+	for _, tt := range tests {
+		if tt.code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, tt.code, tt.want)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("handler called %d times for rejected deliveries", n)
+	}
+}
+
+func TestHandlerReplay(t *testing.T) {
+	h := NewHandler(testSecret, WithClock(func() time.Time { return testNow }))
+	var calls atomic.Int32
+	fail := true
+	h.OnRecord(RecordCreated, func(ctx context.Context, ev *RecordEvent) error {
+		calls.Add(1)
+		if fail {
+			return errors.New("database down")
+		}
+		return nil
+	})
+
+	payload := recordCreated("evt_1")
+	if code := deliver(h, payload, testNow); code != http.StatusInternalServerError {
+		t.Fatalf("failing handler: status = %d, want 500", code)
+	}
+	fail = false
+	if code := deliver(h, payload, testNow); code != http.StatusNoContent {
+		t.Fatalf("redelivery after failure: status = %d, want 204", code)
+	}
+	if code := deliver(h, payload, testNow); code != http.StatusOK {
+		t.Fatalf("redelivery after success: status = %d, want 200", code)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+
+	// Outside the replay window the ID is forgotten
+	later := testNow.Add(3 * DefaultTolerance)
+	h.now = func() time.Time { return later }
+	if code := deliver(h, payload, later); code != http.StatusNoContent {
+		t.Errorf("redelivery after the replay window: status = %d, want 204", code)
+	}
+}
+
+func TestHandlerReplayInProgress(t *testing.T) {
+	for _, firstFails := range []bool{false, true} {
+		t.Run(fmt.Sprintf("first fails=%v", firstFails), func(t *testing.T) {
+			h := NewHandler(testSecret, WithClock(func() time.Time { return testNow }))
+			started, release := make(chan struct{}), make(chan struct{})
+			var calls atomic.Int32
+			h.OnRecord(RecordCreated, func(ctx context.Context, ev *RecordEvent) error {
+				if calls.Add(1) == 1 {
+					close(started)
+					<-release
+					if firstFails {
+						return errors.New("database down")
+					}
+				}
+				return nil
+			})
+
+			payload := recordCreated("evt_1")
+			first := make(chan int)
+			go func() { first <- deliver(h, payload, testNow) }()
+			<-started
+			if code := deliver(h, payload, testNow); code != http.StatusConflict {
+				t.Errorf("redelivery while the first is running: status = %d, want 409", code)
+			}
+			close(release)
+
+			want, wantRetry := http.StatusNoContent, http.StatusOK
+			if firstFails {
+				want, wantRetry = http.StatusInternalServerError, http.StatusNoContent
+			}
+			if code := <-first; code != want {
+				t.Errorf("first delivery: status = %d, want %d", code, want)
+			}
+			if code := deliver(h, payload, testNow); code != wantRetry {
+				t.Errorf("retry after the first finished: status = %d, want %d", code, wantRetry)
+			}
+		})
+	}
+}
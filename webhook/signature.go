@@ -0,0 +1,95 @@
+// signature.go
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the request header carrying the webhook signature, in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>". Several v1 entries may be present while
+// the server rotates secrets; any one matching is sufficient.
+const SignatureHeader = "X-Nebula-Signature"
+
+// DefaultTolerance is the default replay window: how far a signature's timestamp may
+// differ from the receiver's clock.
+const DefaultTolerance = 5 * time.Minute
+
+// Verification errors returned by Verify (and reported by Handler as 401 responses), and
+// the replay errors of Handler.Parse (reported as 200 and 409 responses).
+var (
+	ErrMissingSignature    = errors.New("webhook: missing or malformed signature header")
+	ErrInvalidSignature    = errors.New("webhook: signature does not match payload")
+	ErrTimestampOutOfRange = errors.New("webhook: signature timestamp outside tolerance")
+	ErrReplayed            = errors.New("webhook: event already received")
+	ErrInProgress          = errors.New("webhook: event is still being processed")
+)
+
+// Sign computes the signature header value for payload sent at timestamp t.
+// Use it to sign test payloads, or with NewSignedRequest.
+func Sign(secret []byte, payload []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeMAC(secret, ts, payload))
+}
+
+// Verify checks that header is a valid signature of payload under secret and that its
+// timestamp lies within tolerance of now. A tolerance <= 0 disables the timestamp check.
+func Verify(secret []byte, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	ts, sigs, err := parseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrMissingSignature
+	}
+	if tolerance > 0 {
+		if diff := now.Sub(time.Unix(unix, 0)); diff > tolerance || diff < -tolerance {
+			return fmt.Errorf("%w (off by %s)", ErrTimestampOutOfRange, diff.Round(time.Second))
+		}
+	}
+
+	expected := []byte(computeMAC(secret, ts, payload))
+	for _, sig := range sigs {
+		if hmac.Equal(expected, []byte(sig)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// computeMAC returns the hex HMAC-SHA256 of "<timestamp>.<payload>".
+func computeMAC(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseSignatureHeader splits a signature header into its timestamp and v1 signatures.
+func parseSignatureHeader(header string) (timestamp string, sigs []string, err error) {
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			sigs = append(sigs, value)
+		}
+	}
+	if timestamp == "" || len(sigs) == 0 {
+		return "", nil, ErrMissingSignature
+	}
+	return timestamp, sigs, nil
+}
@@ -0,0 +1,52 @@
+// signature_test.go
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	payload := []byte(`{"id":"evt_1","type":"record.created"}`)
+	now := time.Unix(1_700_000_000, 0)
+	valid := Sign(secret, payload, now)
+	_, mac, _ := strings.Cut(valid, ",v1=")
+	ts := "t=1700000000"
+
+	tests := []struct {
+		name      string
+		header    string
+		payload   []byte
+		tolerance time.Duration
+		now       time.Time
+		want      error
+	}{
+		{"valid", valid, payload, DefaultTolerance, now, nil},
+		{"rotated secrets", ts + ",v1=" + strings.Repeat("0", 64) + ",v1=" + mac, payload, DefaultTolerance, now, nil},
+		{"spaces around entries", "t=1700000000, v1=" + mac, payload, DefaultTolerance, now, nil},
+		{"tampered payload", valid, []byte(`{"id":"evt_2"}`), DefaultTolerance, now, ErrInvalidSignature},
+		{"wrong secret", Sign([]byte("other"), payload, now), payload, DefaultTolerance, now, ErrInvalidSignature},
+		{"signature of another timestamp", "t=1700000001,v1=" + mac, payload, DefaultTolerance, now, ErrInvalidSignature},
+		{"empty header", "", payload, DefaultTolerance, now, ErrMissingSignature},
+		{"no signature", ts, payload, DefaultTolerance, now, ErrMissingSignature},
+		{"no timestamp", "v1=" + mac, payload, DefaultTolerance, now, ErrMissingSignature},
+		{"bad timestamp", "t=yesterday,v1=" + mac, payload, DefaultTolerance, now, ErrMissingSignature},
+
+		// Timestamp tolerance, in both directions
+		{"at the tolerance", valid, payload, time.Minute, now.Add(time.Minute), nil},
+		{"too old", valid, payload, time.Minute, now.Add(time.Minute + time.Second), ErrTimestampOutOfRange},
+		{"from the future", valid, payload, time.Minute, now.Add(-time.Minute - time.Second), ErrTimestampOutOfRange},
+		{"tolerance disabled", valid, payload, 0, now.Add(24 * time.Hour), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(secret, tt.header, tt.payload, tt.tolerance, tt.now)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}